@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/afumu/openlink/internal/webcache"
+)
+
+const cacheUsage = `用法: openlink cache <子命令> [选项]
+
+子命令:
+  info                 显示缓存目录、条目数与总大小（默认）
+  list [-match 文本]   列出缓存条目
+  purge [-match 文本] [-older-than 时长] [-stale]
+                       删除缓存条目；不带选项时清空全部缓存
+`
+
+// runCache 实现 openlink cache 子命令，用于查看和清理 web_fetch 的本地缓存。
+func runCache(args []string) error {
+	dir, err := webcache.DefaultDir()
+	if err != nil {
+		return err
+	}
+	cache := webcache.New(dir)
+
+	sub := "info"
+	if len(args) > 0 {
+		sub, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("cache "+sub, flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, cacheUsage) }
+	match := fs.String("match", "", "只处理 URL 包含该文本的条目")
+	olderThan := fs.Duration("older-than", 0, "只删除存储时间早于该时长的条目（如 72h）")
+	stale := fs.Bool("stale", false, "只删除已过期的条目")
+
+	switch sub {
+	case "info":
+		entries, err := cache.List()
+		if err != nil {
+			return err
+		}
+		var total int64
+		for _, e := range entries {
+			total += e.Size
+		}
+		fmt.Printf("缓存目录: %s\n条目数: %d\n总大小: %d bytes\n", dir, len(entries), total)
+	case "list":
+		fs.Parse(args)
+		entries, err := cache.List()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "STORED\tSTATUS\tSIZE\tFRESH\tURL")
+		for _, e := range entries {
+			if *match != "" && !strings.Contains(e.URL, *match) {
+				continue
+			}
+			fresh := "stale"
+			if cache.Fresh(e) {
+				fresh = "fresh"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", e.StoredAt.Local().Format("2006-01-02 15:04"), e.StatusCode, e.Size, fresh, e.URL)
+		}
+		return w.Flush()
+	case "purge":
+		fs.Parse(args)
+		cutoff := time.Now().Add(-*olderThan)
+		n, err := cache.Purge(func(e webcache.Entry) bool {
+			if *match != "" && !strings.Contains(e.URL, *match) {
+				return false
+			}
+			if *olderThan > 0 && e.StoredAt.After(cutoff) {
+				return false
+			}
+			return !*stale || !cache.Fresh(e)
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已删除 %d 个缓存条目\n", n)
+	default:
+		fmt.Fprint(os.Stderr, cacheUsage)
+		return fmt.Errorf("未知子命令: %s", sub)
+	}
+	return nil
+}
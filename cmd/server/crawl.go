@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/afumu/openlink/internal/tool"
+	"github.com/afumu/openlink/internal/types"
+	"github.com/afumu/openlink/internal/webcache"
+)
+
+// runCrawl 实现 openlink crawl 子命令：与 web_crawl 工具相同，但不受工具调用超时限制。
+func runCrawl(args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("crawl", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "用法: openlink crawl [选项] <URL>\n\n抓取文档站点并保存到 <工作目录>/.openlink/docs/<host>/\n\n选项:")
+		fs.PrintDefaults()
+	}
+	dir := fs.String("dir", cwd, "工作目录")
+	depth := fs.Int("depth", 2, "从起始页开始跟随链接的深度（最大 5）")
+	maxPages := fs.Int("max-pages", 100, "最多保存的页面数（最大 500）")
+	prefix := fs.String("prefix", "", "只跟随路径以此开头的链接（默认：起始页所在目录）")
+	delay := fs.Int("delay", 500, "对同一主机相邻请求的最小间隔（毫秒）")
+	offline := fs.Bool("offline", false, "离线模式：只从本地缓存读取")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	project, err := types.LoadProjectConfig(*dir)
+	if err != nil {
+		return err
+	}
+	if *offline {
+		if _, err := webcache.DefaultDir(); err != nil {
+			return fmt.Errorf("离线模式需要本地网页缓存: %w", err)
+		}
+	}
+	config := &types.Config{RootDir: *dir, Project: project, Offline: *offline}
+	crawl := tool.NewWebCrawlTool(config)
+	toolArgs := map[string]interface{}{
+		"url":       fs.Arg(0),
+		"depth":     strconv.Itoa(*depth),
+		"max_pages": strconv.Itoa(*maxPages),
+		"delay_ms":  strconv.Itoa(*delay),
+	}
+	if *prefix != "" {
+		toolArgs["prefix"] = *prefix
+	}
+	if err := crawl.Validate(toolArgs); err != nil {
+		return err
+	}
+	result := crawl.Execute(&tool.Context{Args: toolArgs, Config: config})
+	if result.Status != "success" {
+		return fmt.Errorf("%s", result.Error)
+	}
+	fmt.Println(result.Output)
+	return nil
+}
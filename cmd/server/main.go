@@ -5,14 +5,34 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/afumu/openlink/internal/security"
 	"github.com/afumu/openlink/internal/server"
 	"github.com/afumu/openlink/internal/types"
+	"github.com/afumu/openlink/internal/webcache"
 	"github.com/afumu/openlink/prompts"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		var run func([]string) error
+		switch os.Args[1] {
+		case "cache":
+			run = runCache
+		case "crawl":
+			run = runCrawl
+		case "skill":
+			run = runSkill
+		}
+		if run != nil {
+			if err := run(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
 	cwd, err := os.Getwd()
 	if err != nil {
 		log.Fatal(err)
@@ -20,6 +40,7 @@ func main() {
 	dir := flag.String("dir", cwd, "工作目录")
 	port := flag.Int("port", 39527, "端口")
 	timeout := flag.Int("timeout", 60, "超时(秒)")
+	offline := flag.Bool("offline", false, "离线模式：web_fetch 只从本地缓存读取")
 	flag.Parse()
 
 	token, err := security.LoadOrCreateToken()
@@ -27,18 +48,43 @@ func main() {
 		log.Fatal(err)
 	}
 
+	settings, err := security.LoadSettings()
+	if err != nil {
+		log.Fatal(err)
+	}
+	project, err := types.LoadProjectConfig(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *offline {
+		if _, err := webcache.DefaultDir(); err != nil {
+			log.Fatalf("离线模式需要本地网页缓存: %v", err)
+		}
+	}
+
 	config := &types.Config{
 		RootDir:       *dir,
 		Port:          *port,
 		Timeout:       *timeout,
 		Token:         token,
 		DefaultPrompt: prompts.DefaultPrompt,
+		Project:       project,
+		Offline:       *offline,
+	}
+
+	if settings.Search != nil {
+		config.Search = *settings.Search
+	}
+
+	if len(project.HTTPAllowHosts) > 0 {
+		fmt.Printf("http_request 允许访问的主机（来自 %s）: %s\n", types.ProjectConfigFile, strings.Join(project.HTTPAllowHosts, ", "))
 	}
 
 	fmt.Printf("\n认证 URL: http://127.0.0.1:%d/auth?token=%s\n", *port, token)
 	fmt.Printf("请在浏览器扩展中输入此 URL\n\n")
 
 	srv := server.New(config)
+	answerFromTerminal(srv.Questions())
 
 	if err := srv.Run(); err != nil {
 		log.Fatalf("服务器运行出错: %v", err)
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/afumu/openlink/internal/skill"
+	"github.com/afumu/openlink/internal/types"
+)
+
+const skillUsage = `用法: openlink skill <子命令> [选项]
+
+子命令:
+  list [--all]                 列出生效的 skill；--all 同时列出被同名 skill 覆盖的条目
+  lint                         检查 frontmatter 错误、缺少 description 和同名覆盖
+  install <来源> [--project] [--force] [-ref 分支] [-path 子目录]
+                               从本地目录、zip/tar 压缩包或 git 地址安装 skill
+  remove <名称> [--project]     删除已安装的 skill
+  update [名称] [--project]     按锁文件记录的来源重新安装（省略名称时更新全部）
+  validate <目录>               检查 skill 目录能否被安装
+
+install/remove/update 默认作用于 ~/.openlink/skills，--project 时作用于 <工作目录>/.openlink/skills，
+来源与版本记录在安装目录的 skills-lock.json 中。
+
+通用选项:
+  -dir 目录                     工作目录（默认当前目录）
+`
+
+// runSkill 实现 openlink skill 子命令，用于查看、检查和安装 skill。
+func runSkill(args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	// 扫描日志面向服务端，命令行输出中省略
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	sub := "list"
+	if len(args) > 0 {
+		sub, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("skill "+sub, flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, skillUsage) }
+	dir := fs.String("dir", cwd, "工作目录")
+	all := fs.Bool("all", false, "同时列出被覆盖的 skill")
+	project := fs.Bool("project", false, "安装到项目目录而非用户目录")
+	force := fs.Bool("force", false, "覆盖同名的已安装 skill")
+	ref := fs.String("ref", "", "git 分支或标签")
+	path := fs.String("path", "", "来源中 skill 所在的子目录")
+	pos := parseInterspersed(fs, args)
+
+	installDir := func() (string, error) {
+		if *project {
+			return skill.ProjectInstallDir(*dir), nil
+		}
+		return skill.UserInstallDir()
+	}
+	needArgs := func(min, max int) {
+		if len(pos) < min || len(pos) > max {
+			fs.Usage()
+			os.Exit(2)
+		}
+	}
+
+	switch sub {
+	case "list":
+		needArgs(0, 0)
+		return listSkills(*dir, *all)
+	case "lint":
+		needArgs(0, 0)
+		warnings := skill.Lint(skill.NewIndex(skillDirs(*dir)).All())
+		for _, w := range warnings {
+			fmt.Println(w)
+		}
+		if len(warnings) > 0 {
+			return fmt.Errorf("发现 %d 个问题", len(warnings))
+		}
+		fmt.Println("没有发现问题")
+	case "install":
+		needArgs(1, 1)
+		target, err := installDir()
+		if err != nil {
+			return err
+		}
+		info, err := skill.Install(target, pos[0], skill.InstallOptions{Ref: *ref, Path: *path, Force: *force})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已安装 %s %s 到 %s\n", info.Name, info.Version, info.Dir)
+		warnSkillDirs(*dir, target)
+	case "remove":
+		needArgs(1, 1)
+		target, err := installDir()
+		if err != nil {
+			return err
+		}
+		if err := skill.Remove(target, pos[0]); err != nil {
+			return err
+		}
+		fmt.Printf("已删除 %s\n", pos[0])
+	case "update":
+		needArgs(0, 1)
+		target, err := installDir()
+		if err != nil {
+			return err
+		}
+		name := ""
+		if len(pos) == 1 {
+			name = pos[0]
+		}
+		updated, err := skill.Update(target, name)
+		for _, info := range updated {
+			fmt.Printf("已更新 %s %s\n", info.Name, info.Version)
+		}
+		if err != nil {
+			return err
+		}
+		if len(updated) == 0 {
+			fmt.Printf("%s 中没有通过 install 安装的 skill\n", target)
+		}
+	case "validate":
+		needArgs(1, 1)
+		info, warnings, err := skill.Validate(pos[0])
+		if err != nil {
+			return err
+		}
+		for _, w := range warnings {
+			fmt.Println(w)
+		}
+		fmt.Printf("%s: skill %q 可以安装\n", info.Location, info.Name)
+	default:
+		fmt.Fprint(os.Stderr, skillUsage)
+		return fmt.Errorf("未知子命令: %s", sub)
+	}
+	return nil
+}
+
+func listSkills(dir string, all bool) error {
+	// 通过 install 安装的 skill 附上锁文件中记录的来源
+	sources := map[string]string{}
+	userDir, _ := skill.UserInstallDir()
+	for _, d := range []string{userDir, skill.ProjectInstallDir(dir)} {
+		if lock, err := skill.ReadLock(d); err == nil {
+			for name, e := range lock.Skills {
+				sources[d+"\x00"+name] = e.Source
+			}
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tSTATUS\tLOCATION\tINSTALLED FROM\tDESCRIPTION")
+	for _, sk := range skill.NewIndex(skillDirs(dir)).All() {
+		status := "active"
+		if sk.ShadowedBy != "" {
+			if !all {
+				continue
+			}
+			status = "shadowed by " + sk.ShadowedBy
+		}
+		from := sources[sk.Source+"\x00"+sk.Name]
+		if from == "" {
+			from = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", sk.Name, orDash(sk.Version), status, sk.Location, from, firstLine(sk.Description))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if all {
+		fmt.Println("\n扫描顺序（先找到的同名 skill 生效）:")
+		for i, d := range skillDirs(dir) {
+			fmt.Printf("  %d. %s\n", i+1, d)
+		}
+	}
+	return nil
+}
+
+// skillDirs 按项目配置返回 dir 的 skill 扫描目录；配置无法读取时使用默认目录。
+func skillDirs(dir string) []string {
+	pc, err := types.LoadProjectConfig(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "警告: %v，使用默认 skill 目录\n", err)
+	}
+	return skill.Dirs(dir, pc)
+}
+
+// warnSkillDirs 在安装目录不在扫描范围内（项目配置了 skill_dirs）时提示。
+func warnSkillDirs(dir, target string) {
+	for _, d := range skillDirs(dir) {
+		if d == target {
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "注意: %s 不在当前项目的 skill_dirs 中，安装的 skill 不会被加载；修改 skill_dirs 后需重启 openlink\n", target)
+}
+
+// parseInterspersed 解析允许出现在位置参数之后的选项，返回位置参数。
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var pos []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return pos
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func firstLine(s string) string {
+	for i, r := range s {
+		if r == '\n' {
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/afumu/openlink/internal/tool"
+)
+
+// answerFromTerminal 在终端打印 question 工具发起的问题；标准输入是终端时，
+// 读取输入作为对最早一个待回答问题的回答，与浏览器扩展中的弹窗二选一即可。
+func answerFromTerminal(broker *tool.QuestionBroker) {
+	interactive := isTerminal(os.Stdin)
+	broker.OnAsk = func(q tool.Question) {
+		var b strings.Builder
+		fmt.Fprintf(&b, "\n[提问 %s] %s\n", q.ID, q.Question)
+		for i, opt := range q.Options {
+			fmt.Fprintf(&b, "  %d. %s\n", i+1, opt)
+		}
+		if interactive {
+			switch {
+			case len(q.Options) > 0 && q.Multiple:
+				b.WriteString("输入选项编号（多个以逗号分隔）")
+			case len(q.Options) > 0:
+				b.WriteString("输入选项编号")
+			default:
+				b.WriteString("输入回答")
+			}
+			if len(q.Options) > 0 && q.AllowText {
+				b.WriteString("或直接输入回答")
+			}
+			b.WriteString("，也可以在浏览器中回答；输入 /cancel 取消：\n")
+		}
+		fmt.Print(b.String())
+	}
+	if !interactive {
+		return
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			pending := broker.Pending("")
+			if len(pending) == 0 {
+				fmt.Println("当前没有待回答的问题")
+				continue
+			}
+			q := pending[0]
+			answer := tool.Answer{Cancel: true}
+			if line != "/cancel" {
+				var err error
+				if answer, err = q.ParseReply(line); err != nil {
+					fmt.Printf("无效的回答：%v\n", err)
+					continue
+				}
+			}
+			if err := broker.Answer(q.ID, answer); err != nil {
+				fmt.Printf("回答失败：%v\n", err)
+			}
+		}
+	}()
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
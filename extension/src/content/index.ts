@@ -1,7 +1,6 @@
-function parseOptions(raw: unknown): string[] {
-  if (Array.isArray(raw)) return raw;
-  if (typeof raw === 'string') { try { return JSON.parse(raw); } catch { return []; } }
-  return [];
+// 以对话页面 URL 作为会话标识，服务端据此跟踪文件读取状态
+function sessionKey(): string {
+  return location.host + location.pathname;
 }
 
 function getNativeSetter() {
@@ -142,11 +141,12 @@ function markExecuted(key: string): void {
 }
 
 async function executeToolCallRaw(toolCall: any): Promise<string> {
+  if (toolCall.name === 'question') return askQuestion(toolCall);
   const { authToken, apiUrl } = await chrome.storage.local.get(['authToken', 'apiUrl']);
   if (!apiUrl) return '请先在插件中配置 API 地址';
   const headers: any = { 'Content-Type': 'application/json' };
   if (authToken) headers['Authorization'] = `Bearer ${authToken}`;
-  const response = await bgFetch(`${apiUrl}/exec`, { method: 'POST', headers, body: JSON.stringify(toolCall) });
+  const response = await bgFetch(`${apiUrl}/exec`, { method: 'POST', headers, body: JSON.stringify({ ...toolCall, session: sessionKey() }) });
   if (response.status === 401) return '认证失败，请在插件中重新输入 Token';
   if (!response.ok) return `[OpenLink 错误] HTTP ${response.status}`;
   const result = JSON.parse(response.body);
@@ -385,7 +385,11 @@ async function sendInitPrompt() {
   if (!apiUrl) { alert('请先在插件中配置 API 地址'); return; }
   const headers: any = { 'Content-Type': 'application/json' };
   if (authToken) headers['Authorization'] = `Bearer ${authToken}`;
-  const resp = await bgFetch(`${apiUrl}/prompt`, { headers });
+  // 输入框中已有的用户消息作为 q 传给服务端，提示词中只列出最相关的 skill
+  const editor = querySelectorFirst(getSiteConfig().editor);
+  const draft = editor ? getEditorText(editor).trim().slice(0, 2000) : '';
+  const url = draft ? `${apiUrl}/prompt?q=${encodeURIComponent(draft)}` : `${apiUrl}/prompt`;
+  const resp = await bgFetch(url, { headers });
   if (!resp.ok) { alert('获取初始化提示词失败'); return; }
 
   if (location.hostname.includes('aistudio.google.com')) {
@@ -417,37 +421,140 @@ async function fillAiStudioSystemInstructions(prompt: string) {
   if (closeBtn) closeBtn.click();
 }
 
-function showQuestionPopup(question: string, options: string[]): Promise<string> {
-  return new Promise(resolve => {
-    const overlay = document.createElement('div');
-    overlay.style.cssText = 'position:fixed;inset:0;background:rgba(0,0,0,0.5);z-index:2147483647;display:flex;align-items:center;justify-content:center';
-    const box = document.createElement('div');
-    box.style.cssText = 'background:#1e1e2e;color:#cdd6f4;border-radius:12px;padding:24px;max-width:480px;width:90%;box-shadow:0 8px 32px rgba(0,0,0,0.5)';
-    const title = document.createElement('p');
-    title.style.cssText = 'margin:0 0 16px;font-size:15px;line-height:1.5;white-space:pre-wrap';
-    title.textContent = question;
-    box.appendChild(title);
-    options.forEach((opt, i) => {
-      const btn = document.createElement('button');
-      btn.textContent = `${i + 1}. ${opt}`;
-      btn.style.cssText = 'display:block;width:100%;margin-bottom:8px;padding:10px 14px;background:#313244;color:#cdd6f4;border:1px solid #45475a;border-radius:8px;cursor:pointer;font-size:13px;text-align:left';
-      btn.onmouseenter = () => { btn.style.background = '#45475a'; };
-      btn.onmouseleave = () => { btn.style.background = '#313244'; };
-      btn.onclick = () => { overlay.remove(); resolve(opt); };
-      box.appendChild(btn);
-    });
-    overlay.appendChild(box);
-    document.body.appendChild(overlay);
+interface PendingQuestion {
+  id: string;
+  question: string;
+  options?: string[];
+  multiple: boolean;
+  allow_text: boolean;
+}
+
+interface QuestionAnswer {
+  choices?: string[];
+  text?: string;
+  cancel?: boolean;
+}
+
+// showQuestionPopup 显示提问弹窗；submit 返回错误信息时保留弹窗并提示，返回 null 时关闭弹窗
+function showQuestionPopup(q: PendingQuestion, submit: (a: QuestionAnswer) => Promise<string | null>): { close: () => void } {
+  const overlay = document.createElement('div');
+  overlay.style.cssText = 'position:fixed;inset:0;background:rgba(0,0,0,0.5);z-index:2147483647;display:flex;align-items:center;justify-content:center';
+  const box = document.createElement('div');
+  box.style.cssText = 'background:#1e1e2e;color:#cdd6f4;border-radius:12px;padding:24px;max-width:480px;width:90%;box-shadow:0 8px 32px rgba(0,0,0,0.5)';
+  const title = document.createElement('p');
+  title.style.cssText = 'margin:0 0 16px;font-size:15px;line-height:1.5;white-space:pre-wrap';
+  title.textContent = q.question;
+  box.appendChild(title);
+
+  const errorEl = document.createElement('p');
+  errorEl.style.cssText = 'margin:8px 0 0;color:#f38ba8;font-size:12px;display:none';
+  const btnStyle = 'display:block;width:100%;margin-bottom:8px;padding:10px 14px;background:#313244;color:#cdd6f4;border:1px solid #45475a;border-radius:8px;cursor:pointer;font-size:13px;text-align:left';
+  const send = async (a: QuestionAnswer) => {
+    const err = await submit(a);
+    if (err === null) { overlay.remove(); return; }
+    errorEl.textContent = err;
+    errorEl.style.display = 'block';
+  };
+
+  const options = q.options ?? [];
+  const checked = new Set<string>();
+  options.forEach((opt, i) => {
+    if (q.multiple) {
+      const label = document.createElement('label');
+      label.style.cssText = btnStyle + ';display:flex;gap:8px;align-items:center';
+      const cb = document.createElement('input');
+      cb.type = 'checkbox';
+      cb.onchange = () => { if (cb.checked) checked.add(opt); else checked.delete(opt); };
+      label.appendChild(cb);
+      label.appendChild(document.createTextNode(`${i + 1}. ${opt}`));
+      box.appendChild(label);
+      return;
+    }
+    const btn = document.createElement('button');
+    btn.textContent = `${i + 1}. ${opt}`;
+    btn.style.cssText = btnStyle;
+    btn.onmouseenter = () => { btn.style.background = '#45475a'; };
+    btn.onmouseleave = () => { btn.style.background = '#313244'; };
+    btn.onclick = () => send({ choices: [opt] });
+    box.appendChild(btn);
   });
+
+  let input: HTMLTextAreaElement | null = null;
+  if (q.allow_text) {
+    input = document.createElement('textarea');
+    input.rows = 3;
+    input.placeholder = options.length > 0 ? '或输入其他回答' : '输入回答';
+    input.style.cssText = 'width:100%;box-sizing:border-box;margin-bottom:8px;padding:8px;background:#313244;color:#cdd6f4;border:1px solid #45475a;border-radius:8px;font-size:13px;resize:vertical';
+    box.appendChild(input);
+  }
+
+  const actions = document.createElement('div');
+  actions.style.cssText = 'display:flex;gap:8px;justify-content:flex-end;margin-top:8px';
+  const cancelBtn = document.createElement('button');
+  cancelBtn.textContent = '取消';
+  cancelBtn.style.cssText = 'background:#313244;color:#f38ba8;border:1px solid #f38ba8;border-radius:6px;padding:6px 14px;cursor:pointer;font-size:13px';
+  cancelBtn.onclick = () => send({ cancel: true });
+  actions.appendChild(cancelBtn);
+  if (q.multiple || q.allow_text) {
+    const okBtn = document.createElement('button');
+    okBtn.textContent = '提交';
+    okBtn.style.cssText = 'background:#1677ff;color:#fff;border:none;border-radius:6px;padding:6px 14px;cursor:pointer;font-size:13px';
+    okBtn.onclick = () => send({ choices: [...checked], text: input?.value.trim() ?? '' });
+    actions.appendChild(okBtn);
+  }
+  box.appendChild(actions);
+  box.appendChild(errorEl);
+  overlay.appendChild(box);
+  document.body.appendChild(overlay);
+  return { close: () => overlay.remove() };
+}
+
+// askQuestion 执行 question 工具：/exec 会阻塞到用户回答为止，期间轮询待回答的问题并弹窗，
+// 用户也可以在运行 openlink 的终端中回答，此时弹窗随 /exec 返回自动关闭
+async function askQuestion(toolCall: any): Promise<string> {
+  const { authToken, apiUrl } = await chrome.storage.local.get(['authToken', 'apiUrl']);
+  if (!apiUrl) return '请先在插件中配置 API 地址';
+  const headers: any = { 'Content-Type': 'application/json' };
+  if (authToken) headers['Authorization'] = `Bearer ${authToken}`;
+
+  const session = sessionKey();
+  const before = await bgFetch(`${apiUrl}/questions?session=${encodeURIComponent(session)}`, { headers });
+  const seen = new Set<string>(before.ok ? (JSON.parse(before.body).questions ?? []).map((q: PendingQuestion) => q.id) : []);
+
+  let finished = false;
+  let popup: { close: () => void } | null = null;
+  const exec = bgFetch(`${apiUrl}/exec`, { method: 'POST', headers, body: JSON.stringify({ ...toolCall, session }) })
+    .finally(() => { finished = true; });
+
+  while (!finished && !popup) {
+    await new Promise(r => setTimeout(r, 500));
+    if (finished) break;
+    const resp = await bgFetch(`${apiUrl}/questions?session=${encodeURIComponent(session)}`, { headers });
+    if (!resp.ok) continue;
+    const q = (JSON.parse(resp.body).questions ?? []).find((q: PendingQuestion) => !seen.has(q.id));
+    if (!q || finished) continue;
+    popup = showQuestionPopup(q, async a => {
+      const r = await bgFetch(`${apiUrl}/questions/${encodeURIComponent(q.id)}`, { method: 'POST', headers, body: JSON.stringify(a) });
+      if (r.ok || r.status === 404) return null;
+      try { return JSON.parse(r.body).error ?? `HTTP ${r.status}`; } catch { return `HTTP ${r.status}`; }
+    });
+  }
+
+  const response = await exec;
+  popup?.close();
+  if (response.status === 401) return '认证失败，请在插件中重新输入 Token';
+  if (!response.ok) return `[OpenLink 错误] HTTP ${response.status}`;
+  const result = JSON.parse(response.body);
+  return result.output || result.error || '[OpenLink] 空响应';
 }
 
 async function executeToolCall(toolCall: any) {
   if (toolCall.name === 'question') {
-    const q: string = toolCall.args?.question ?? '';
-    const rawOpts = toolCall.args?.options;
-    const opts: string[] = parseOptions(rawOpts);
-    const answer = opts.length > 0 ? await showQuestionPopup(q, opts) : (prompt(q) ?? '');
-    fillAndSend(answer, false);
+    try {
+      fillAndSend(await askQuestion(toolCall), false);
+    } catch (error) {
+      fillAndSend(`[OpenLink 错误] ${error}`, false);
+    }
     return;
   }
 
@@ -461,7 +568,7 @@ async function executeToolCall(toolCall: any) {
     const response = await bgFetch(`${apiUrl}/exec`, {
       method: 'POST',
       headers,
-      body: JSON.stringify(toolCall)
+      body: JSON.stringify({ ...toolCall, session: sessionKey() })
     });
 
     if (response.status === 401) { fillAndSend('认证失败，请在插件中重新输入 Token', false); return; }
@@ -586,12 +693,19 @@ async function fillAndSend(result: string, autoSend = false) {
 
 // ── 斜杠命令 / @ 文件补全 ──────────────────────────────────────────────────────
 
-let skillsCache: Array<{ name: string; description: string }> | null = null;
+interface SkillArgument { name: string; description?: string; required?: boolean; default?: string }
+interface SkillItem { name: string; description: string; arguments?: SkillArgument[]; argument_hint?: string }
+
+interface CommandItem { name: string; description: string; arguments?: SkillArgument[]; argument_hint?: string; takes_args?: boolean }
+
+let skillsCache: SkillItem[] | null = null;
 let skillsCacheTime = 0;
+let commandsCache: CommandItem[] | null = null;
+let commandsCacheTime = 0;
 const filesCache = new Map<string, { ts: number; files: string[] }>();
 const FILES_TTL = 5000;
 
-async function fetchSkills(): Promise<Array<{ name: string; description: string }>> {
+async function fetchSkills(): Promise<SkillItem[]> {
   if (skillsCache && Date.now() - skillsCacheTime < 30000) return skillsCache;
   const { authToken, apiUrl } = await chrome.storage.local.get(['authToken', 'apiUrl']);
   if (!apiUrl) return [];
@@ -607,6 +721,47 @@ async function fetchSkills(): Promise<Array<{ name: string; description: string
   } catch { return []; }
 }
 
+async function fetchCommands(): Promise<CommandItem[]> {
+  if (commandsCache && Date.now() - commandsCacheTime < 30000) return commandsCache;
+  const { authToken, apiUrl } = await chrome.storage.local.get(['authToken', 'apiUrl']);
+  if (!apiUrl) return [];
+  const headers: any = {};
+  if (authToken) headers['Authorization'] = `Bearer ${authToken}`;
+  try {
+    const resp = await bgFetch(`${apiUrl}/commands`, { headers });
+    if (!resp.ok) return [];
+    commandsCache = JSON.parse(resp.body).commands || [];
+    commandsCacheTime = Date.now();
+    return commandsCache!;
+  } catch { return []; }
+}
+
+// renderCommand 请求服务端展开斜杠命令（替换参数、附上 @文件 内容），失败时提示并返回 null
+async function renderCommand(name: string, args: string): Promise<string | null> {
+  const { authToken, apiUrl } = await chrome.storage.local.get(['authToken', 'apiUrl']);
+  if (!apiUrl) return null;
+  const headers: any = { 'Content-Type': 'application/json' };
+  if (authToken) headers['Authorization'] = `Bearer ${authToken}`;
+  const resp = await bgFetch(`${apiUrl}/commands/${encodeURIComponent(name)}/render`, { method: 'POST', headers, body: JSON.stringify({ args }) });
+  let data: any = {};
+  try { data = JSON.parse(resp.body); } catch {}
+  if (!resp.ok) { alert(`命令 /${name} 展开失败：${data.error || resp.status}`); return null; }
+  return data.text;
+}
+
+// fetchSkillMatches 按相关度向服务端查询匹配的 skill（名称、描述、标签、关键词）
+async function fetchSkillMatches(q: string): Promise<SkillItem[]> {
+  const { authToken, apiUrl } = await chrome.storage.local.get(['authToken', 'apiUrl']);
+  if (!apiUrl) return [];
+  const headers: any = {};
+  if (authToken) headers['Authorization'] = `Bearer ${authToken}`;
+  try {
+    const resp = await bgFetch(`${apiUrl}/skills/match?q=${encodeURIComponent(q)}&limit=10`, { headers });
+    if (!resp.ok) return [];
+    return JSON.parse(resp.body).matches || [];
+  } catch { return []; }
+}
+
 async function fetchFiles(q: string): Promise<string[]> {
   const cached = filesCache.get(q);
   if (cached && Date.now() - cached.ts < FILES_TTL) return cached.files;
@@ -810,6 +965,17 @@ function replaceTokenInEditor(el: HTMLElement, token: string, replacement: strin
   }
 }
 
+// skillCallXml 生成加载 skill 的工具调用；声明了参数时附带 args 模板，供用户填写
+function skillCallXml(s: SkillItem): string {
+  let xml = `<tool name="skill">\n  <parameter name="skill">${s.name}</parameter>\n`;
+  if (s.arguments && s.arguments.length > 0) {
+    const args: Record<string, string> = {};
+    for (const a of s.arguments) args[a.name] = a.default ?? '';
+    xml += `  <parameter name="args">${JSON.stringify(args)}</parameter>\n`;
+  }
+  return xml + '</tool>';
+}
+
 function attachInputListener(editorEl: HTMLElement) {
   const { fillMethod } = getSiteConfig();
   let destroyPicker: (() => void) | null = null;
@@ -825,25 +991,54 @@ function attachInputListener(editorEl: HTMLElement) {
     const pos = getCaretPosition(editorEl);
     const before = text.slice(0, pos);
 
-    const slashMatch = before.match(/(?:^|[\s\n\u00a0])(\/([\w-]*))$/);
+    const slashMatch = before.match(/(?:^|[\s\n\u00a0])(\/([\w:-]*))$/);
     if (slashMatch) {
       const token = slashMatch[1];
       const query = slashMatch[2].toLowerCase();
-      const skills = await fetchSkills();
+      const [skills, commands] = await Promise.all([fetchSkills(), fetchCommands()]);
       if (currentVersion !== inputVersion) return;
+      const filteredCommands = commands.filter(c => c.name.toLowerCase().includes(query) || c.description.toLowerCase().includes(query));
       const filtered = query
         ? skills.filter(s => s.name.toLowerCase().includes(query) || s.description.toLowerCase().includes(query))
         : skills;
+      if (query) {
+        // 子串匹配之后补充按相关度排序的结果，覆盖关键词、标签等字段
+        const matches = await fetchSkillMatches(query.replace(/[-_:]/g, ' '));
+        if (currentVersion !== inputVersion) return;
+        for (const m of matches) {
+          if (!filtered.some(s => s.name === m.name)) filtered.push(m);
+        }
+      }
       dismiss();
-      if (filtered.length === 0) return;
+      if (filtered.length === 0 && filteredCommands.length === 0) return;
+      // 命令的 value 以 / 开头，skill 的 value 是工具调用 XML
       destroyPicker = showPickerPopup(
         editorEl,
-        filtered.map(s => ({
-          label: s.name,
-          sub: s.description,
-          value: `<tool name="skill">\n  <parameter name="skill">${s.name}</parameter>\n</tool>`,
-        })),
-        (xml) => { replaceTokenInEditor(editorEl, token, xml, fillMethod); dismiss(); },
+        [
+          ...filteredCommands.map(c => ({
+            label: c.argument_hint ? `/${c.name} ${c.argument_hint}` : `/${c.name}`,
+            sub: c.description,
+            value: `/${c.name}`,
+          })),
+          ...filtered.map(s => ({
+            label: s.argument_hint ? `${s.name} ${s.argument_hint}` : s.name,
+            sub: s.description,
+            value: skillCallXml(s),
+          })),
+        ],
+        async (value) => {
+          dismiss();
+          if (!value.startsWith('/')) { replaceTokenInEditor(editorEl, token, value, fillMethod); return; }
+          const cmd = filteredCommands.find(c => `/${c.name}` === value)!;
+          let args = '';
+          if (cmd.takes_args) {
+            const input = prompt(cmd.argument_hint ? `${value} ${cmd.argument_hint}` : value, '');
+            if (input === null) return;
+            args = input;
+          }
+          const text = await renderCommand(cmd.name, args);
+          if (text !== null) replaceTokenInEditor(editorEl, token, text, fillMethod);
+        },
         dismiss
       );
       return;
@@ -0,0 +1,229 @@
+// Package command 加载自定义斜杠命令：.openlink/commands/ 下的 Markdown 提示词模板。
+// 文件格式与 SKILL.md 相同（frontmatter + 正文），展开后由插件插入聊天输入框。
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/afumu/openlink/internal/security"
+	"github.com/afumu/openlink/internal/skill"
+)
+
+// 展开 @文件 引用时的限制
+const (
+	maxFileRefs     = 10
+	maxFileRefBytes = 64 * 1024
+)
+
+type Command struct {
+	Name         string // 相对命令目录的路径去掉 .md，子目录以 : 分隔，如 git:commit
+	Description  string
+	Arguments    []skill.Argument
+	ArgumentHint string
+	TakesArgs    bool   // 声明了参数或正文引用了 $ARGUMENTS/{{name}}
+	Location     string // absolute path to the .md file
+	Source       string // 所在的命令目录
+}
+
+// Dirs 返回工作目录的命令目录，按优先级从高到低排列：项目目录优先于用户目录。
+func Dirs(rootDir string) []string {
+	home, _ := os.UserHomeDir()
+	return []string{
+		filepath.Join(rootDir, ".openlink", "commands"),
+		filepath.Join(home, ".openlink", "commands"),
+	}
+}
+
+// Load 返回工作目录可用的全部命令，按名称排序；同名命令以优先级高的目录为准。
+func Load(rootDir string) []Command {
+	return LoadDirs(Dirs(rootDir))
+}
+
+// LoadDirs 按 dirs 顺序扫描命令目录。
+func LoadDirs(dirs []string) []Command {
+	seen := map[string]bool{}
+	var cmds []Command
+	for _, dir := range dirs {
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if strings.HasPrefix(d.Name(), ".") && path != dir {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+				return nil
+			}
+			rel, _ := filepath.Rel(dir, path)
+			name := strings.ReplaceAll(filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel))), "/", ":")
+			key := strings.ToLower(name)
+			if seen[key] {
+				log.Printf("[Command] %s 被同名命令覆盖，已忽略", path)
+				return nil
+			}
+			cmd, err := load(path, name)
+			if err != nil {
+				log.Printf("[Command] %s: %v", path, err)
+				return nil
+			}
+			cmd.Source = dir
+			seen[key] = true
+			cmds = append(cmds, cmd)
+			return nil
+		})
+	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+	return cmds
+}
+
+func load(path, name string) (Command, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Command{}, err
+	}
+	info, body, err := skill.Parse(path, string(data))
+	if err != nil {
+		return Command{}, err
+	}
+	cmd := Command{
+		Name:         name,
+		Description:  info.Description,
+		Arguments:    info.Arguments,
+		ArgumentHint: skill.ArgumentHint(info),
+		TakesArgs:    len(info.Arguments) > 0 || info.ArgumentHint != "" || skill.UsesArguments(body),
+		Location:     path,
+	}
+	if cmd.Description == "" {
+		// 没有 description 时取正文第一行非空文本
+		for _, line := range strings.Split(body, "\n") {
+			if line = strings.TrimSpace(strings.TrimLeft(line, "# ")); line != "" {
+				cmd.Description = line
+				break
+			}
+		}
+	}
+	return cmd, nil
+}
+
+// Get 按名称查找命令（大小写不敏感），名称可带前导 /。
+func Get(rootDir, name string) (Command, bool) {
+	name = strings.TrimPrefix(name, "/")
+	for _, cmd := range Load(rootDir) {
+		if strings.EqualFold(cmd.Name, name) {
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
+
+// Render 展开命令：替换正文中的 $ARGUMENTS 与 {{name}} 占位符，
+// 并把模板正文中 @路径 引用的工作目录内文件附在末尾；参数中的 @路径 不会展开。
+func Render(rootDir string, cmd Command, named map[string]string, raw string) (string, error) {
+	data, err := os.ReadFile(cmd.Location)
+	if err != nil {
+		return "", err
+	}
+	info, body, err := skill.Parse(cmd.Location, string(data))
+	if err != nil {
+		return "", err
+	}
+	info.Name = cmd.Name
+	files := expandFileRefs(rootDir, body)
+	text, err := skill.RenderAs("command", body, info, named, raw)
+	if err != nil {
+		return "", err
+	}
+	text = strings.TrimSpace(text)
+	if files != "" {
+		text += "\n\n" + files
+	}
+	return text, nil
+}
+
+var fileRefRe = regexp.MustCompile(`(?:^|\s)@([^\s@]+)`)
+
+// expandFileRefs 读取 text 中 @路径 引用的文件，路径相对工作目录，不能越出工作目录。
+// 不存在或不是普通文件的引用原样保留，不会报错。
+func expandFileRefs(rootDir, text string) string {
+	var sb strings.Builder
+	seen := map[string]bool{}
+	expanded := 0
+	for _, m := range fileRefRe.FindAllStringSubmatch(text, -1) {
+		rel := strings.TrimRight(m[1], ".,;:!?)]}\"'")
+		if rel == "" || seen[rel] {
+			continue
+		}
+		seen[rel] = true
+		content, err := readFileRef(rootDir, rel)
+		if err != nil {
+			continue
+		}
+		if expanded == maxFileRefs {
+			fmt.Fprintf(&sb, "[已达到 %d 个文件引用上限，其余引用未展开]\n", maxFileRefs)
+			break
+		}
+		expanded++
+		fence := codeFence(content)
+		fmt.Fprintf(&sb, "文件 %s：\n%s\n%s\n%s\n\n", rel, fence, content, fence)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// codeFence 返回比 content 中最长的连续反引号更长的围栏，至少三个反引号。
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+func readFileRef(rootDir, rel string) (string, error) {
+	path, err := security.SafePath(rootDir, rel)
+	if err != nil {
+		return "", err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !fi.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", rel)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	buf := make([]byte, min(fi.Size(), maxFileRefBytes))
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	buf = buf[:n]
+	if bytes.IndexByte(buf, 0) >= 0 {
+		return "", fmt.Errorf("%s is not a text file", rel)
+	}
+	content := strings.TrimRight(string(buf), "\n")
+	if fi.Size() > maxFileRefBytes {
+		content = strings.ToValidUTF8(content, "") + fmt.Sprintf("\n[truncated, file is %d bytes]", fi.Size())
+	}
+	return content, nil
+}
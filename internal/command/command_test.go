@@ -0,0 +1,91 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeCommand(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDirs(t *testing.T) {
+	project, user := t.TempDir(), t.TempDir()
+	writeCommand(t, project, "review.md", "---\ndescription: Review the diff\n---\nReview $ARGUMENTS")
+	writeCommand(t, project, "git/commit.md", "# Write a commit message\n\nUse conventional commits.")
+	writeCommand(t, project, ".draft.md", "ignored")
+	writeCommand(t, project, "notes.txt", "ignored")
+	writeCommand(t, user, "Review.md", "---\ndescription: user version\n---\n")
+	writeCommand(t, user, "write-tests.md", "---\narguments:\n  - name: file\n    required: true\n---\nWrite tests for {{file}}")
+
+	cmds := LoadDirs([]string{project, user})
+	var names []string
+	for _, c := range cmds {
+		names = append(names, c.Name)
+	}
+	if got := strings.Join(names, ","); got != "git:commit,review,write-tests" {
+		t.Fatalf("names = %s", got)
+	}
+	if cmds[0].Description != "Write a commit message" {
+		t.Errorf("description from first line = %q", cmds[0].Description)
+	}
+	if cmds[1].Description != "Review the diff" || cmds[1].Source != project {
+		t.Errorf("project command should win: %+v", cmds[1])
+	}
+	if cmds[2].ArgumentHint != "<file>" {
+		t.Errorf("hint = %q", cmds[2].ArgumentHint)
+	}
+	for i, want := range []bool{false, true, true} {
+		if cmds[i].TakesArgs != want {
+			t.Errorf("%s: TakesArgs = %v, want %v", cmds[i].Name, cmds[i].TakesArgs, want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".openlink", "commands")
+	writeCommand(t, dir, "review.md", "---\ndescription: Review\nargument-hint: <files...>\n---\nReview $ARGUMENTS against @STYLE.md.\n")
+	writeCommand(t, dir, "write-tests.md", "---\narguments:\n  - name: file\n    required: true\n---\nWrite tests for {{file}}")
+	writeCommand(t, root, "STYLE.md", "Use tabs.\n```go\nx := 1\n```\n")
+	writeCommand(t, root, "main.go", "package main\n")
+	writeCommand(t, root, "logo.png", "\x89PNG\x00")
+
+	cmd, ok := Get(root, "/review")
+	if !ok || cmd.ArgumentHint != "<files...>" {
+		t.Fatalf("get: %+v %v", cmd, ok)
+	}
+	out, err := Render(root, cmd, nil, "@main.go @logo.png @../secret @missing.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Review @main.go @logo.png @../secret @missing.go against @STYLE.md.",
+		"文件 STYLE.md：\n````\nUse tabs.\n```go\nx := 1\n```\n````",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in %q", want, out)
+		}
+	}
+	// 参数中的 @路径 不展开，只展开模板正文里的引用
+	if strings.Contains(out, "PNG") || strings.Contains(out, "文件 main.go") || strings.Contains(out, "文件 ../secret") {
+		t.Errorf("unexpected file content in %q", out)
+	}
+
+	cmd, _ = Get(root, "write-tests")
+	if _, err := Render(root, cmd, nil, ""); err == nil || !strings.Contains(err.Error(), `command "write-tests": missing required argument file`) {
+		t.Errorf("expected missing argument error, got %v", err)
+	}
+	if out, err := Render(root, cmd, map[string]string{"file": "a.go"}, ""); err != nil || out != "Write tests for a.go" {
+		t.Errorf("got %q %v", out, err)
+	}
+}
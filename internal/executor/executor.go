@@ -16,13 +16,21 @@ import (
 type Executor struct {
 	config    *types.Config
 	registry  *tool.Registry
+	files     *tool.FileTracker
+	questions *tool.QuestionBroker
+	todos     *tool.TodoStore
+	skills    *tool.ActiveSkills
 	callCount atomic.Int64
 }
 
 func New(config *types.Config) *Executor {
 	e := &Executor{
-		config:   config,
-		registry: tool.NewRegistry(),
+		config:    config,
+		registry:  tool.NewRegistry(),
+		files:     tool.NewFileTracker(),
+		questions: tool.NewQuestionBroker(),
+		todos:     tool.NewTodoStore(config.RootDir),
+		skills:    tool.NewActiveSkills(),
 	}
 	e.registry.Register(tool.NewExecCmdTool(config))
 	e.registry.Register(tool.NewListDirTool(config))
@@ -31,10 +39,16 @@ func New(config *types.Config) *Executor {
 	e.registry.Register(tool.NewGlobTool(config))
 	e.registry.Register(tool.NewGrepTool(config))
 	e.registry.Register(tool.NewEditTool(config))
-	e.registry.Register(tool.NewWebFetchTool())
+	e.registry.Register(tool.NewWebFetchTool(config))
+	e.registry.Register(tool.NewWebCrawlTool(config))
+	e.registry.Register(tool.NewWebSearchTool(config))
+	e.registry.Register(tool.NewHTTPRequestTool(config))
 	e.registry.Register(tool.NewQuestionTool())
 	e.registry.Register(tool.NewSkillTool(config))
-	e.registry.Register(tool.NewTodoWriteTool(config))
+	e.registry.Register(tool.NewSkillResourceTool(config))
+	e.registry.Register(tool.NewSkillSearchTool(config))
+	e.registry.Register(tool.NewTodoWriteTool(config, e.todos))
+	e.registry.Register(tool.NewTodoReadTool(config, e.todos))
 	return e
 }
 
@@ -56,14 +70,23 @@ func (e *Executor) Execute(ctx context.Context, req *types.ToolRequest) *types.T
 		return &types.ToolResponse{Status: "error", Output: msg, Error: msg}
 	}
 
+	if err := e.skills.Check(req.Session, t.Name()); err != nil {
+		return &types.ToolResponse{Status: "error", Output: err.Error(), Error: err.Error()}
+	}
+
 	if err := t.Validate(req.Args); err != nil {
 		msg := fmt.Sprintf("validation failed: %s", err)
 		return &types.ToolResponse{Status: "error", Output: msg, Error: msg}
 	}
 
 	result := t.Execute(&tool.Context{
-		Args:   req.Args,
-		Config: e.config,
+		Args:      req.Args,
+		Config:    e.config,
+		Session:   req.Session,
+		Files:     e.files,
+		Questions: e.questions,
+		Skills:    e.skills,
+		Done:      ctx.Done(),
 	})
 
 	resp := &types.ToolResponse{
@@ -87,10 +110,26 @@ func (e *Executor) Execute(ctx context.Context, req *types.ToolRequest) *types.T
 	} else {
 		resp.Output += reminder
 	}
+	// 有未完成的待办时随提醒附上清单，todo 工具自身的输出已包含清单
+	if req.Name != "todo_write" && req.Name != "todo_read" {
+		if todos, err := e.todos.Load(req.Session); err == nil && tool.HasOpenTodos(todos) {
+			resp.Output += "\n\n[系统提示] " + tool.RenderTodos(todos)
+		}
+	}
 
 	return resp
 }
 
+// Questions 返回 question 工具使用的问题登记中心，供 HTTP 接口与终端回答问题。
+func (e *Executor) Questions() *tool.QuestionBroker {
+	return e.questions
+}
+
+// Todos 返回会话待办事项的存储，供 /todos 接口读取。
+func (e *Executor) Todos() *tool.TodoStore {
+	return e.todos
+}
+
 func (e *Executor) ListTools() []tool.ToolInfo {
 	return e.registry.List()
 }
@@ -2,6 +2,9 @@ package executor
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/afumu/openlink/internal/types"
@@ -50,4 +53,58 @@ func TestExecutor(t *testing.T) {
 			t.Error("expected tools to be registered")
 		}
 	})
+	t.Run("open todos are appended to reminders", func(t *testing.T) {
+		e := New(testConfig(t))
+		e.Execute(context.Background(), &types.ToolRequest{
+			Name:    "todo_write",
+			Session: "s1",
+			Args:    map[string]interface{}{"todos": `[{"content":"write tests","status":"in_progress"}]`},
+		})
+		resp := e.Execute(context.Background(), &types.ToolRequest{
+			Name:    "exec_cmd",
+			Session: "s1",
+			Args:    map[string]interface{}{"command": "echo hello"},
+		})
+		if !strings.Contains(resp.Output, "- [~] 1. write tests") {
+			t.Errorf("expected todos in output, got %q", resp.Output)
+		}
+		other := e.Execute(context.Background(), &types.ToolRequest{
+			Name:    "exec_cmd",
+			Session: "s2",
+			Args:    map[string]interface{}{"command": "echo hello"},
+		})
+		if strings.Contains(other.Output, "write tests") {
+			t.Errorf("todos leaked into another session: %q", other.Output)
+		}
+	})
+	t.Run("active skill restricts tools to allowed-tools", func(t *testing.T) {
+		cfg := testConfig(t)
+		dir := filepath.Join(cfg.RootDir, ".skills", "readonly")
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nallowed-tools: [read_file, list_dir]\n---\nbody"), 0644)
+		e := New(cfg)
+		run := func(session, name string, args map[string]interface{}) *types.ToolResponse {
+			return e.Execute(context.Background(), &types.ToolRequest{Name: name, Session: session, Args: args})
+		}
+
+		if resp := run("s1", "skill", map[string]interface{}{"skill": "readonly"}); resp.Status != "success" {
+			t.Fatalf("load skill: %s", resp.Error)
+		}
+		if resp := run("s1", "exec_cmd", map[string]interface{}{"command": "echo hi"}); resp.Status != "error" || !strings.Contains(resp.Error, "readonly") {
+			t.Errorf("expected exec_cmd to be blocked, got %+v", resp)
+		}
+		if resp := run("s1", "list_dir", map[string]interface{}{"path": "."}); resp.Status != "success" {
+			t.Errorf("allowed tool failed: %s", resp.Error)
+		}
+		if resp := run("s1", "skill_resource", map[string]interface{}{"skill": "readonly"}); resp.Status != "success" {
+			t.Errorf("skill_resource should stay available: %s", resp.Error)
+		}
+		if resp := run("s2", "exec_cmd", map[string]interface{}{"command": "echo hi"}); resp.Status != "success" {
+			t.Errorf("other session blocked: %s", resp.Error)
+		}
+		run("s1", "skill", map[string]interface{}{"release": "true"})
+		if resp := run("s1", "exec_cmd", map[string]interface{}{"command": "echo hi"}); resp.Status != "success" {
+			t.Errorf("released session still blocked: %s", resp.Error)
+		}
+	})
 }
@@ -0,0 +1,218 @@
+// Package instructions 查找用户和项目的指令文件（AGENTS.md、OPENLINK.md、CLAUDE.md、.cursor/rules），
+// 合并后附加到初始化提示词中。
+package instructions
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/afumu/openlink/internal/walk"
+)
+
+// FileNames 是每个目录中依次查找的指令文件。
+var FileNames = []string{"AGENTS.md", "OPENLINK.md", "CLAUDE.md", ".cursorrules"}
+
+// 大小限制：单个文件超过 maxFileBytes 截断，合计超过 maxTotalBytes 后其余文件只列出路径。
+const (
+	maxFileBytes   = 32 * 1024
+	maxTotalBytes  = 96 * 1024
+	maxSubdirDepth = 3
+	maxSubdirs     = 2000
+)
+
+// File 是一个指令文件。
+type File struct {
+	Path  string // 绝对路径
+	Label string // 显示用路径：工作目录内为相对路径，用户目录内以 ~/ 开头
+	Scope string // 作用范围，工作目录的子目录中的文件为该子目录的相对路径
+}
+
+// Discover 按从全局到具体的顺序返回指令文件：用户主目录（及 ~/.openlink）、
+// 从主目录到工作目录路径上的各级目录、工作目录，最后是工作目录下 maxSubdirDepth 层以内的子目录。
+// 同一文件（含符号链接）只出现一次。
+func Discover(rootDir, home string) []File {
+	rootDir = filepath.Clean(rootDir)
+	var dirs []string
+	if home != "" {
+		home = filepath.Clean(home)
+		dirs = append(dirs, filepath.Join(home, ".openlink"))
+		if rel, err := filepath.Rel(home, rootDir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			dirs = append(dirs, home)
+			if rel != "." {
+				cur := home
+				for _, part := range strings.Split(rel, string(filepath.Separator)) {
+					cur = filepath.Join(cur, part)
+					dirs = append(dirs, cur)
+				}
+			}
+		} else {
+			dirs = append(dirs, home, rootDir)
+		}
+	} else {
+		dirs = append(dirs, rootDir)
+	}
+
+	seen := map[string]bool{}
+	var files []File
+	add := func(dir, scope string) {
+		for _, f := range filesIn(dir) {
+			real, err := filepath.EvalSymlinks(f.Path)
+			if err != nil || seen[real] {
+				continue
+			}
+			seen[real] = true
+			f.Label = label(f.Path, rootDir, home)
+			f.Scope = scope
+			files = append(files, f)
+		}
+	}
+	for _, d := range dirs {
+		add(d, "")
+	}
+	for _, d := range subdirs(rootDir) {
+		rel, _ := filepath.Rel(rootDir, d)
+		add(d, filepath.ToSlash(rel)+"/")
+	}
+	return files
+}
+
+// filesIn 返回目录中的指令文件，.cursor/rules 下的 .mdc/.md 规则排在最后。
+func filesIn(dir string) []File {
+	var files []File
+	for _, name := range FileNames {
+		p := filepath.Join(dir, name)
+		if fi, err := os.Stat(p); err == nil && fi.Mode().IsRegular() {
+			files = append(files, File{Path: p})
+		}
+	}
+	var rules []string
+	filepath.WalkDir(filepath.Join(dir, ".cursor", "rules"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); !d.IsDir() && (ext == ".mdc" || ext == ".md") {
+			rules = append(rules, path)
+		}
+		return nil
+	})
+	sort.Strings(rules)
+	for _, p := range rules {
+		files = append(files, File{Path: p})
+	}
+	return files
+}
+
+// subdirs 返回工作目录下 maxSubdirDepth 层以内的子目录，跳过隐藏目录和依赖、构建目录。
+func subdirs(rootDir string) []string {
+	var dirs []string
+	filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == rootDir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || walk.SkipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		if len(dirs) == maxSubdirs {
+			return filepath.SkipAll
+		}
+		dirs = append(dirs, path)
+		if rel, _ := filepath.Rel(rootDir, path); strings.Count(rel, string(filepath.Separator))+1 >= maxSubdirDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return dirs
+}
+
+func label(path, rootDir, home string) string {
+	if rel, err := filepath.Rel(rootDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	if home != "" {
+		if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return "~/" + filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
+
+// Build 读取 Discover 找到的指令文件并合并为一段提示词，没有指令文件时返回空字符串。
+// 每个文件带来源标题；内容完全相同的文件只保留第一个。
+func Build(rootDir, home string) string {
+	files := Discover(rootDir, home)
+	if len(files) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	var skipped []string
+	contents := map[string]bool{}
+	total := 0
+	for _, f := range files {
+		data, err := os.ReadFile(f.Path)
+		if err != nil {
+			continue
+		}
+		body, globs := stripFrontmatter(string(data))
+		body = strings.TrimSpace(body)
+		if body == "" || contents[body] {
+			continue
+		}
+		contents[body] = true
+		if total+min(len(body), maxFileBytes) > maxTotalBytes {
+			skipped = append(skipped, f.Label)
+			continue
+		}
+		if len(body) > maxFileBytes {
+			body = strings.ToValidUTF8(body[:maxFileBytes], "") + fmt.Sprintf("\n\n[已截断，完整内容共 %d 字节，可用 read_file 读取]", len(data))
+		}
+		total += len(body)
+
+		fmt.Fprintf(&sb, "### 来源：%s", f.Label)
+		switch {
+		case f.Scope != "" && globs != "":
+			fmt.Fprintf(&sb, "（适用于 %s 下的 %s）", f.Scope, globs)
+		case f.Scope != "":
+			fmt.Fprintf(&sb, "（适用于 %s）", f.Scope)
+		case globs != "":
+			fmt.Fprintf(&sb, "（适用于 %s）", globs)
+		}
+		sb.WriteString("\n\n" + body + "\n\n")
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&sb, "以下指令文件超出长度限制未载入，需要时用 read_file 读取：%s\n", strings.Join(skipped, "、"))
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "## 项目指令\n\n以下内容来自用户和项目的指令文件，按从全局到具体的顺序排列，冲突时以靠后、更具体的为准；标注了适用范围的只在处理对应文件时遵循。\n\n" + strings.TrimSpace(sb.String())
+}
+
+// Load 使用当前用户的主目录调用 Build。
+func Load(rootDir string) string {
+	home, _ := os.UserHomeDir()
+	return Build(rootDir, home)
+}
+
+// stripFrontmatter 去掉 .mdc 规则开头的 --- 块，并取出其中的 globs。
+// Cursor 的 globs 常写成未加引号的 *.ts，不是合法 YAML，因此按行读取。
+func stripFrontmatter(content string) (body, globs string) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	first, rest, ok := strings.Cut(content, "\n")
+	if !ok || strings.TrimSpace(first) != "---" {
+		return content, ""
+	}
+	lines := strings.Split(rest, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "---" {
+			return strings.Join(lines[i+1:], "\n"), globs
+		}
+		if k, v, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(k) == "globs" {
+			globs = strings.Trim(strings.TrimSpace(v), `"'`)
+		}
+	}
+	return content, ""
+}
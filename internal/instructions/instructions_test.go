@@ -0,0 +1,89 @@
+package instructions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscoverOrder(t *testing.T) {
+	home := t.TempDir()
+	root := filepath.Join(home, "code", "app")
+	write(t, filepath.Join(home, ".openlink", "OPENLINK.md"), "global")
+	write(t, filepath.Join(home, "code", "AGENTS.md"), "workspace")
+	write(t, filepath.Join(root, "CLAUDE.md"), "project claude")
+	write(t, filepath.Join(root, "AGENTS.md"), "project agents")
+	write(t, filepath.Join(root, ".cursor", "rules", "go.mdc"), "---\ndescription: Go\nglobs: *.go\n---\nUse gofmt.")
+	write(t, filepath.Join(root, "pkg", "api", "AGENTS.md"), "api rules")
+	write(t, filepath.Join(root, "node_modules", "x", "AGENTS.md"), "ignored")
+	write(t, filepath.Join(root, "a", "b", "c", "d", "AGENTS.md"), "too deep")
+	if err := os.Symlink(filepath.Join(root, "AGENTS.md"), filepath.Join(root, "OPENLINK.md")); err != nil {
+		t.Skip("symlinks not supported")
+	}
+
+	var labels []string
+	for _, f := range Discover(root, home) {
+		labels = append(labels, f.Label)
+	}
+	want := "~/.openlink/OPENLINK.md,~/code/AGENTS.md,AGENTS.md,CLAUDE.md,.cursor/rules/go.mdc,pkg/api/AGENTS.md"
+	if got := strings.Join(labels, ","); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	home, root := t.TempDir(), t.TempDir()
+	if Build(root, home) != "" {
+		t.Error("expected empty output without instruction files")
+	}
+
+	write(t, filepath.Join(root, "AGENTS.md"), "Run `make test` before committing.")
+	write(t, filepath.Join(root, "CLAUDE.md"), "Run `make test` before committing.\n")
+	write(t, filepath.Join(root, ".cursor", "rules", "ts.mdc"), "---\nglobs: \"*.ts\"\nalwaysApply: false\n---\nPrefer const.")
+	write(t, filepath.Join(root, "web", "AGENTS.md"), "Use pnpm.")
+	write(t, filepath.Join(root, "big", "AGENTS.md"), strings.Repeat("x", maxFileBytes+10))
+	out := Build(root, home)
+
+	for _, want := range []string{
+		"## 项目指令",
+		"### 来源：AGENTS.md\n\nRun `make test` before committing.",
+		"### 来源：.cursor/rules/ts.mdc（适用于 *.ts）\n\nPrefer const.",
+		"### 来源：web/AGENTS.md（适用于 web/）\n\nUse pnpm.",
+		"[已截断，完整内容共",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "来源：CLAUDE.md") {
+		t.Error("duplicate content should be skipped")
+	}
+	if strings.Contains(out, "alwaysApply") {
+		t.Error("frontmatter should be stripped")
+	}
+}
+
+func TestBuildTotalLimit(t *testing.T) {
+	home, root := t.TempDir(), t.TempDir()
+	for i := 0; i < 5; i++ {
+		write(t, filepath.Join(root, string(rune('a'+i)), "AGENTS.md"), strings.Repeat(string(rune('a'+i)), maxFileBytes-1))
+	}
+	out := Build(root, home)
+	if len(out) > maxTotalBytes+4096 {
+		t.Errorf("output too large: %d bytes", len(out))
+	}
+	if !strings.Contains(out, "超出长度限制未载入") || !strings.Contains(out, "e/AGENTS.md") {
+		t.Errorf("expected skipped files to be listed:\n%s", out[len(out)-300:])
+	}
+}
@@ -0,0 +1,218 @@
+// Package search 提供 web_search 工具使用的可插拔搜索后端。
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/afumu/openlink/internal/security"
+	"github.com/afumu/openlink/internal/types"
+)
+
+// Result 是归一化后的一条搜索结果。
+type Result struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet"`
+}
+
+// Backend 是搜索后端。
+type Backend interface {
+	Search(ctx context.Context, query string, limit int) ([]Result, error)
+}
+
+// ErrNotConfigured 表示未在 settings.json 中配置搜索后端。
+var ErrNotConfigured = errors.New(`web_search is not configured; add a "search" section to ~/.openlink/settings.json`)
+
+// maxResponseBytes 是读取后端响应的上限。
+const maxResponseBytes = 4 * 1024 * 1024
+
+// New 按配置创建搜索后端；client 为 nil 时使用 http.DefaultClient。
+// 后端地址由用户自行配置（可以是本机的 SearXNG 实例），因此不经过 web_fetch 的地址限制。
+func New(cfg types.SearchConfig, client *http.Client) (Backend, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	switch cfg.Backend {
+	case "":
+		return nil, ErrNotConfigured
+	case "searxng":
+		if cfg.URL == "" {
+			return nil, errors.New("search.url is required for the searxng backend")
+		}
+		return &SearXNG{BaseURL: cfg.URL, Headers: cfg.Headers, Client: client}, nil
+	case "http":
+		if !strings.Contains(cfg.URL, "{query}") {
+			return nil, errors.New("search.url must contain a {query} placeholder for the http backend")
+		}
+		return &Template{
+			URL:          cfg.URL,
+			Headers:      cfg.Headers,
+			ResultsPath:  cfg.ResultsPath,
+			TitleField:   cfg.TitleField,
+			URLField:     cfg.URLField,
+			SnippetField: cfg.SnippetField,
+			Client:       client,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown search backend %q (expected searxng or http)", cfg.Backend)
+}
+
+// SearXNG 调用 SearXNG 兼容实例的 /search?format=json 接口。
+type SearXNG struct {
+	BaseURL string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+func (s *SearXNG) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	u, err := url.Parse(strings.TrimSuffix(s.BaseURL, "/") + "/search")
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("q", query)
+	q.Set("format", "json")
+	u.RawQuery = q.Encode()
+
+	var resp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := getJSON(ctx, s.Client, u.String(), s.Headers, &resp); err != nil {
+		return nil, err
+	}
+	var out []Result
+	for _, r := range resp.Results {
+		out = append(out, Result{Title: r.Title, URL: r.URL, Snippet: r.Content})
+		if len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Template 调用任意返回 JSON 的搜索接口：URL 中的 {query}、{limit} 被替换为转义后的值，
+// ResultsPath（点分路径，如 "web.results"）指向结果数组，三个 Field 指定结果对象中的字段名。
+type Template struct {
+	URL          string
+	Headers      map[string]string
+	ResultsPath  string
+	TitleField   string
+	URLField     string
+	SnippetField string
+	Client       *http.Client
+}
+
+func (t *Template) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	u := strings.NewReplacer("{query}", url.QueryEscape(query), "{limit}", strconv.Itoa(limit)).Replace(t.URL)
+	var doc interface{}
+	if err := getJSON(ctx, t.Client, u, t.Headers, &doc); err != nil {
+		return nil, err
+	}
+
+	node := doc
+	if t.ResultsPath != "" {
+		for _, key := range strings.Split(t.ResultsPath, ".") {
+			obj, ok := node.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("results_path %q not found in response", t.ResultsPath)
+			}
+			node = obj[key]
+		}
+	}
+	items, ok := node.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("results_path %q does not point to an array", t.ResultsPath)
+	}
+
+	field := func(obj map[string]interface{}, name, fallback string) string {
+		if name == "" {
+			name = fallback
+		}
+		s, _ := obj[name].(string)
+		return s
+	}
+	var out []Result
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		out = append(out, Result{
+			Title:   field(obj, t.TitleField, "title"),
+			URL:     field(obj, t.URLField, "url"),
+			Snippet: field(obj, t.SnippetField, "snippet"),
+		})
+		if len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// getJSON 发送 GET 请求并解析 JSON 响应。错误信息中的请求地址与请求头取值会被打码，
+// 避免把 API Key 带给模型。
+func getJSON(ctx context.Context, client *http.Client, rawURL string, headers map[string]string, v interface{}) error {
+	secrets := make([]string, 0, len(headers))
+	for _, val := range headers {
+		secrets = append(secrets, val)
+	}
+	fail := func(err error) error {
+		return errors.New(security.RedactText(strings.ReplaceAll(err.Error(), rawURL, security.RedactURL(rawURL)), secrets...))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fail(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, val := range headers {
+		req.Header.Set(k, val)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fail(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fail(fmt.Errorf("search backend returned %s", resp.Status))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return fail(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fail(fmt.Errorf("search backend returned invalid JSON: %w", err))
+	}
+	return nil
+}
+
+// Stub 是返回固定结果的后端，用于测试。
+type Stub struct {
+	Results []Result
+	Err     error
+	// Queries 记录收到的查询
+	Queries []string
+}
+
+func (s *Stub) Search(_ context.Context, query string, limit int) ([]Result, error) {
+	s.Queries = append(s.Queries, query)
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	if len(s.Results) > limit {
+		return s.Results[:limit], nil
+	}
+	return s.Results, nil
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/afumu/openlink/internal/types"
+)
+
+func TestSearXNG(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" || r.URL.Query().Get("format") != "json" || r.URL.Query().Get("q") != "go generics" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write([]byte(`{"results":[
+			{"title":"Tutorial","url":"https://go.dev/doc/tutorial/generics","content":"Getting started"},
+			{"title":"Spec","url":"https://go.dev/ref/spec","content":"Type parameters"},
+			{"title":"Blog","url":"https://go.dev/blog/intro-generics","content":"An introduction"}]}`))
+	}))
+	defer srv.Close()
+
+	b, err := New(types.SearchConfig{Backend: "searxng", URL: srv.URL + "/"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := b.Search(context.Background(), "go generics", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[1].URL != "https://go.dev/ref/spec" || results[0].Snippet != "Getting started" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Subscription-Token") != "k-123456" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("q") != "a&b" || r.URL.Query().Get("count") != "5" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"web":{"results":[{"title":"T","url":"https://x.dev/","description":"D"}, "junk"]}}`))
+	}))
+	defer srv.Close()
+
+	cfg := types.SearchConfig{
+		Backend:      "http",
+		URL:          srv.URL + "/res?q={query}&count={limit}",
+		Headers:      map[string]string{"X-Subscription-Token": "k-123456"},
+		ResultsPath:  "web.results",
+		SnippetField: "description",
+	}
+	b, err := New(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := b.Search(context.Background(), "a&b", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0] != (Result{Title: "T", URL: "https://x.dev/", Snippet: "D"}) {
+		t.Errorf("unexpected results: %+v", results)
+	}
+
+	cfg.ResultsPath = "missing.path"
+	b, _ = New(cfg, nil)
+	if _, err := b.Search(context.Background(), "a&b", 5); err == nil {
+		t.Error("expected error for bad results_path")
+	}
+}
+
+func TestErrorsRedactSecrets(t *testing.T) {
+	cfg := types.SearchConfig{
+		Backend: "http",
+		URL:     "http://127.0.0.1:1/search?q={query}&api_key=topsecretkey",
+		Headers: map[string]string{"Authorization": "Bearer hunter2hunter2"},
+	}
+	b, _ := New(cfg, nil)
+	_, err := b.Search(context.Background(), "q", 5)
+	if err == nil {
+		t.Fatal("expected connection error")
+	}
+	if strings.Contains(err.Error(), "topsecretkey") || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("secret leaked in error: %v", err)
+	}
+}
+
+func TestNewConfigErrors(t *testing.T) {
+	bad := []types.SearchConfig{
+		{},
+		{Backend: "searxng"},
+		{Backend: "http", URL: "https://api.example.com/search"},
+		{Backend: "bing"},
+	}
+	for _, cfg := range bad {
+		if _, err := New(cfg, nil); err == nil {
+			t.Errorf("%+v: expected error", cfg)
+		}
+	}
+}
@@ -26,9 +26,9 @@ func LoadOrCreateToken() (string, error) {
 	}
 
 	path := filepath.Join(dir, "settings.json")
+	var settings types.Settings
 	data, err := os.ReadFile(path)
 	if err == nil {
-		var settings types.Settings
 		if err := json.Unmarshal(data, &settings); err == nil && settings.Token != "" {
 			return settings.Token, nil
 		}
@@ -39,10 +39,9 @@ func LoadOrCreateToken() (string, error) {
 		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
 	token := hex.EncodeToString(b)
-	settings := types.Settings{
-		Token:     token,
-		CreatedAt: time.Now().Format(time.RFC3339),
-	}
+	// 保留文件中已有的其他配置（如 search）
+	settings.Token = token
+	settings.CreatedAt = time.Now().Format(time.RFC3339)
 
 	data, err = json.MarshalIndent(settings, "", "  ")
 	if err != nil {
@@ -54,6 +53,27 @@ func LoadOrCreateToken() (string, error) {
 	return token, nil
 }
 
+// LoadSettings 读取 ~/.openlink/settings.json；文件不存在时返回零值。
+func LoadSettings() (types.Settings, error) {
+	var settings types.Settings
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return settings, err
+	}
+	path := filepath.Join(home, ".openlink", "settings.json")
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, err
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	return settings, nil
+}
+
 func AuthMiddleware(token string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Request.URL.Path == "/health" || c.Request.URL.Path == "/auth" {
@@ -0,0 +1,184 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// MaxRedirects 是受保护客户端允许跟随的最大重定向次数。
+const MaxRedirects = 5
+
+// ErrBlockedAddress 表示目标地址属于本机、内网或保留地址段。
+var ErrBlockedAddress = errors.New("requests to private/internal addresses are not allowed")
+
+// reservedPrefixes 是不允许对外请求访问的地址段（IANA 特殊用途地址注册表）。
+var reservedPrefixes = func() []netip.Prefix {
+	cidrs := []string{
+		// IPv4
+		"0.0.0.0/8",       // "本网络"
+		"10.0.0.0/8",      // 私有
+		"100.64.0.0/10",   // 运营商级 NAT
+		"127.0.0.0/8",     // 回环
+		"169.254.0.0/16",  // 链路本地（含云厂商元数据地址）
+		"172.16.0.0/12",   // 私有
+		"192.0.0.0/24",    // IETF 协议分配
+		"192.0.2.0/24",    // 文档 TEST-NET-1
+		"192.88.99.0/24",  // 6to4 中继任播
+		"192.168.0.0/16",  // 私有
+		"198.18.0.0/15",   // 基准测试
+		"198.51.100.0/24", // 文档 TEST-NET-2
+		"203.0.113.0/24",  // 文档 TEST-NET-3
+		"224.0.0.0/4",     // 组播
+		"240.0.0.0/4",     // 保留（含广播地址）
+		// IPv6
+		"::/128",        // 未指定
+		"::1/128",       // 回环
+		"100::/64",      // 丢弃前缀
+		"2001::/23",     // IETF 协议分配
+		"2001:db8::/32", // 文档
+		"fc00::/7",      // 唯一本地地址
+		"fe80::/10",     // 链路本地
+		"fec0::/10",     // 站点本地（已废弃）
+		"ff00::/8",      // 组播
+	}
+	out := make([]netip.Prefix, 0, len(cidrs))
+	for _, c := range cidrs {
+		out = append(out, netip.MustParsePrefix(c))
+	}
+	return out
+}()
+
+var (
+	nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+	sixToFour   = netip.MustParsePrefix("2002::/16")
+)
+
+// IsPublicIP 判断 ip 是否为可公开访问的地址。IPv4 映射地址、NAT64 与 6to4 地址
+// 按其内嵌的 IPv4 地址判断。
+func IsPublicIP(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	return isPublicAddr(addr)
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	if addr.Is6() {
+		b := addr.As16()
+		switch {
+		case nat64Prefix.Contains(addr):
+			return isPublicAddr(netip.AddrFrom4([4]byte{b[12], b[13], b[14], b[15]}))
+		case sixToFour.Contains(addr):
+			return isPublicAddr(netip.AddrFrom4([4]byte{b[2], b[3], b[4], b[5]}))
+		}
+	}
+	for _, p := range reservedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// neverAllowedPrefixes 是即使主机被用户显式放行也拒绝访问的地址段：
+// 链路本地（含云厂商元数据地址）、未指定、组播与保留地址。
+var neverAllowedPrefixes = func() []netip.Prefix {
+	cidrs := []string{
+		"0.0.0.0/8", "169.254.0.0/16", "224.0.0.0/4", "240.0.0.0/4",
+		"::/128", "fe80::/10", "ff00::/8",
+		"fd00:ec2::254/128", // AWS IPv6 元数据地址
+	}
+	out := make([]netip.Prefix, 0, len(cidrs))
+	for _, c := range cidrs {
+		out = append(out, netip.MustParsePrefix(c))
+	}
+	return out
+}()
+
+// IsAllowListable 判断 ip 能否通过显式放行访问：公网、私有和回环地址可以，
+// 链路本地、元数据、组播及保留地址始终拒绝。
+func IsAllowListable(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap().WithZone("")
+	for _, p := range neverAllowedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// IPPolicy 决定是否允许连接到 ip:port。
+type IPPolicy func(ip net.IP, port int) bool
+
+// PublicOnly 是默认策略：只允许公网地址。
+func PublicOnly(ip net.IP, port int) bool { return IsPublicIP(ip) }
+
+// NewGuardedClient 返回一个在建立连接时校验实际连接 IP 的 HTTP 客户端：
+// DNS 重绑定或重定向到内网地址都会在拨号阶段被拒绝。每一跳重定向都会重新校验协议，
+// 最多跟随 MaxRedirects 次；同时禁用环境变量代理，避免绕过校验。policy 为 nil 时使用 PublicOnly。
+func NewGuardedClient(timeout time.Duration, policy IPPolicy) *http.Client {
+	if policy == nil {
+		policy = PublicOnly
+	}
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, portStr, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			port, _ := strconv.Atoi(portStr)
+			if ip == nil || !policy(ip, port) {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 20 * time.Second,
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", MaxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+			}
+			if ip := net.ParseIP(req.URL.Hostname()); ip != nil {
+				port := 80
+				if req.URL.Scheme == "https" {
+					port = 443
+				}
+				if p, err := strconv.Atoi(req.URL.Port()); err == nil {
+					port = p
+				}
+				if !policy(ip, port) {
+					return fmt.Errorf("%w: redirect to %s", ErrBlockedAddress, req.URL.Host)
+				}
+			}
+			return nil
+		},
+	}
+}
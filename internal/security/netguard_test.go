@@ -0,0 +1,97 @@
+package security
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	blocked := []string{
+		"0.0.0.0", "0.1.2.3", "10.1.2.3", "100.64.0.1", "127.0.0.1", "169.254.169.254",
+		"172.16.0.1", "192.168.1.1", "198.18.0.1", "224.0.0.1", "255.255.255.255",
+		"::", "::1", "fe80::1", "fc00::1", "ff02::1",
+		"::ffff:127.0.0.1", "::ffff:10.0.0.1", "64:ff9b::7f00:1", "2002:7f00:1::",
+	}
+	for _, s := range blocked {
+		if IsPublicIP(net.ParseIP(s)) {
+			t.Errorf("%s should be blocked", s)
+		}
+	}
+	allowed := []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111", "::ffff:8.8.8.8", "64:ff9b::808:808"}
+	for _, s := range allowed {
+		if !IsPublicIP(net.ParseIP(s)) {
+			t.Errorf("%s should be allowed", s)
+		}
+	}
+}
+
+// onlyPort 只放行本机上指定端口的监听，模拟"公网"服务器。
+func onlyPort(port int) IPPolicy {
+	return func(ip net.IP, p int) bool { return ip.IsLoopback() && p == port }
+}
+
+func serverPort(t *testing.T, srv *httptest.Server) int {
+	t.Helper()
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return port
+}
+
+func TestGuardedClient(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer internal.Close()
+
+	t.Run("dial to loopback refused", func(t *testing.T) {
+		_, err := NewGuardedClient(5*time.Second, nil).Get(internal.URL)
+		if !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("expected ErrBlockedAddress, got %v", err)
+		}
+	})
+
+	t.Run("hostname resolving to loopback refused at dial time", func(t *testing.T) {
+		u, _ := url.Parse(internal.URL)
+		_, err := NewGuardedClient(5*time.Second, nil).Get("http://localhost:" + u.Port())
+		if !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("expected ErrBlockedAddress, got %v", err)
+		}
+	})
+
+	t.Run("redirect to internal address refused", func(t *testing.T) {
+		public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, internal.URL+"/exec", http.StatusFound)
+		}))
+		defer public.Close()
+		_, err := NewGuardedClient(5*time.Second, onlyPort(serverPort(t, public))).Get(public.URL)
+		if !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("expected ErrBlockedAddress, got %v", err)
+		}
+	})
+
+	t.Run("redirect loop capped", func(t *testing.T) {
+		var loop *httptest.Server
+		loop = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, loop.URL+"/again", http.StatusFound)
+		}))
+		defer loop.Close()
+		_, err := NewGuardedClient(5*time.Second, onlyPort(serverPort(t, loop))).Get(loop.URL)
+		if err == nil {
+			t.Error("expected redirect cap error")
+		}
+	})
+
+	t.Run("allowed address succeeds", func(t *testing.T) {
+		resp, err := NewGuardedClient(5*time.Second, onlyPort(serverPort(t, internal))).Get(internal.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	})
+}
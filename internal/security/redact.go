@@ -0,0 +1,60 @@
+package security
+
+import (
+	"net/url"
+	"strings"
+)
+
+// sensitiveParams 是会被打码的查询参数名（小写）。
+var sensitiveParams = map[string]bool{
+	"api_key": true, "apikey": true, "key": true, "token": true, "access_token": true,
+	"auth": true, "password": true, "passwd": true, "secret": true, "client_secret": true,
+	"sig": true, "signature": true, "x-amz-signature": true, "x-amz-credential": true,
+}
+
+const redacted = "REDACTED"
+
+// RedactURL 去掉 URL 中的用户名密码，并对看起来像凭据的查询参数打码，
+// 用于把外部返回的链接或请求地址展示给模型之前。无法解析时原样返回。
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	changed := false
+	if u.User != nil {
+		u.User = nil
+		changed = true
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			if sensitiveParams[strings.ToLower(k)] {
+				q.Set(k, redacted)
+				changed = true
+			}
+		}
+		if changed {
+			u.RawQuery = q.Encode()
+		}
+	}
+	if !changed {
+		return raw
+	}
+	return u.String()
+}
+
+// RedactText 将 s 中出现的每个 secret 替换为 REDACTED，用于错误信息可能带出
+// 配置中的 API Key 等场景。空字符串与过短的值会被忽略，避免误伤正常文本。
+func RedactText(s string, secrets ...string) string {
+	for _, secret := range secrets {
+		if len(secret) < 4 {
+			continue
+		}
+		s = strings.ReplaceAll(s, secret, redacted)
+		if esc := url.QueryEscape(secret); esc != secret {
+			s = strings.ReplaceAll(s, esc, redacted)
+		}
+	}
+	return s
+}
@@ -0,0 +1,29 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactURL(t *testing.T) {
+	cases := map[string]string{
+		"https://example.com/docs?page=2":                   "https://example.com/docs?page=2",
+		"https://user:pw@example.com/":                      "https://example.com/",
+		"https://api.example.com/s?q=go&api_key=abc123":     "https://api.example.com/s?api_key=REDACTED&q=go",
+		"https://s3.example.com/o?X-Amz-Signature=deadbeef": "https://s3.example.com/o?X-Amz-Signature=REDACTED",
+		"not a url": "not a url",
+	}
+	for in, want := range cases {
+		if got := RedactURL(in); got != want {
+			t.Errorf("RedactURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRedactText(t *testing.T) {
+	msg := `Get "https://api.example.com/s?key=s3cr3t%2Fkey": dial tcp: timeout (token s3cr3t/key)`
+	got := RedactText(msg, "s3cr3t/key", "")
+	if strings.Contains(got, "s3cr3t") {
+		t.Errorf("secret leaked: %q", got)
+	}
+}
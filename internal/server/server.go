@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"crypto/subtle"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
@@ -10,13 +11,18 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/afumu/openlink/internal/command"
 	"github.com/afumu/openlink/internal/executor"
+	"github.com/afumu/openlink/internal/instructions"
 	"github.com/afumu/openlink/internal/security"
 	"github.com/afumu/openlink/internal/skill"
+	"github.com/afumu/openlink/internal/tool"
 	"github.com/afumu/openlink/internal/types"
+	"github.com/afumu/openlink/internal/walk"
 	"github.com/gin-gonic/gin"
 )
 
@@ -44,7 +50,7 @@ func (s *Server) setupRoutes() {
 	s.router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-OpenLink-Session")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
@@ -61,7 +67,13 @@ func (s *Server) setupRoutes() {
 	s.router.POST("/exec", s.handleExec)
 	s.router.GET("/prompt", s.handlePrompt)
 	s.router.GET("/skills", s.handleListSkills)
+	s.router.GET("/skills/match", s.handleMatchSkills)
+	s.router.GET("/commands", s.handleListCommands)
+	s.router.POST("/commands/:name/render", s.handleRenderCommand)
 	s.router.GET("/files", s.handleListFiles)
+	s.router.GET("/todos", s.handleListTodos)
+	s.router.GET("/questions", s.handleListQuestions)
+	s.router.POST("/questions/:id", s.handleAnswerQuestion)
 }
 
 func (s *Server) handleHealth(c *gin.Context) {
@@ -112,13 +124,47 @@ func (s *Server) handlePrompt(c *gin.Context) {
 	}
 	content = []byte(strings.ReplaceAll(string(content), "{{SYSTEM_INFO}}", buildSystemInfo(s.config.RootDir)))
 
-	skills := skill.LoadInfos(s.config.RootDir)
+	if text := instructions.Load(s.config.RootDir); text != "" {
+		content = append(content, []byte("\n\n"+text)...)
+	}
+
+	skills := skill.For(s.config).Infos()
 	if len(skills) > 0 {
 		var sb strings.Builder
 		sb.WriteString("\n\n## 当前可用 Skills\n\n")
-		for _, sk := range skills {
+		// 带 q 参数且 skill 多于 k 个时，只详细列出与用户消息最相关的 skill，其余只列名称
+		listed, rest := skills, []skill.Info(nil)
+		if q := c.Query("q"); q != "" {
+			k := queryInt(c, "k", defaultPromptSkills, maxSkillMatches)
+			if len(skills) > k {
+				listed = nil
+				top := map[string]bool{}
+				for _, m := range skill.Rank(skills, q, k) {
+					listed = append(listed, m.Info)
+					top[m.Info.Location] = true
+				}
+				for _, sk := range skills {
+					if !top[sk.Location] {
+						rest = append(rest, sk)
+					}
+				}
+			}
+		}
+		for _, sk := range listed {
 			sb.WriteString(fmt.Sprintf("- **%s**: %s\n", sk.Name, sk.Description))
 		}
+		if len(rest) > 0 {
+			names := make([]string, 0, len(rest))
+			for _, sk := range rest {
+				names = append(names, sk.Name)
+			}
+			if len(listed) > 0 {
+				sb.WriteString("\n其他 skill")
+			} else {
+				sb.WriteString("可用 skill")
+			}
+			sb.WriteString(fmt.Sprintf("（共 %d 个，如需说明请使用 `skill_search` 工具查找）：%s\n", len(rest), strings.Join(names, "、")))
+		}
 		content = append(content, []byte(sb.String())...)
 	}
 
@@ -145,6 +191,10 @@ func (s *Server) handleExec(c *gin.Context) {
 		return
 	}
 
+	if req.Session == "" {
+		req.Session = c.GetHeader("X-OpenLink-Session")
+	}
+
 	log.Printf("[OpenLink] 工具调用: name=%s, args=%+v\n", req.Name, req.Args)
 
 	// 修复 AI 模型将换行符误写为 \t 的情况（仅对 edit 工具的字符串参数）
@@ -156,7 +206,14 @@ func (s *Server) handleExec(c *gin.Context) {
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.Timeout)*time.Second)
+	// 请求断开时取消调用；question 工具等待用户回答，由自身的 timeout 参数限时
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if req.Name == "question" {
+		ctx, cancel = context.WithCancel(c.Request.Context())
+	} else {
+		ctx, cancel = context.WithTimeout(c.Request.Context(), time.Duration(s.config.Timeout)*time.Second)
+	}
 	defer cancel()
 	resp := s.executor.Execute(ctx, &req)
 
@@ -169,6 +226,48 @@ func (s *Server) handleExec(c *gin.Context) {
 	log.Println("[OpenLink] 响应已发送")
 }
 
+// Questions 返回 question 工具的问题登记中心，供终端回答问题。
+func (s *Server) Questions() *tool.QuestionBroker {
+	return s.executor.Questions()
+}
+
+func (s *Server) handleListTodos(c *gin.Context) {
+	session := c.Query("session")
+	if session == "" {
+		session = c.GetHeader("X-OpenLink-Session")
+	}
+	todos, err := s.executor.Todos().Load(session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if todos == nil {
+		todos = []tool.Todo{}
+	}
+	c.JSON(http.StatusOK, gin.H{"todos": todos, "markdown": tool.RenderTodos(todos)})
+}
+
+func (s *Server) handleListQuestions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"questions": s.executor.Questions().Pending(c.Query("session"))})
+}
+
+func (s *Server) handleAnswerQuestion(c *gin.Context) {
+	var answer tool.Answer
+	if err := c.ShouldBindJSON(&answer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.executor.Questions().Answer(c.Param("id"), answer); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, tool.ErrQuestionNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
 func (s *Server) Run() error {
 	return s.router.Run(fmt.Sprintf("127.0.0.1:%d", s.config.Port))
 }
@@ -191,19 +290,145 @@ func fixTabNewlines(s string) string {
 }
 
 type skillItem struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	AllowedTools []string         `json:"allowed_tools,omitempty"`
+	Version      string           `json:"version,omitempty"`
+	Tags         []string         `json:"tags,omitempty"`
+	Keywords     []string         `json:"keywords,omitempty"`
+	WhenToUse    string           `json:"when_to_use,omitempty"`
+	Arguments    []skill.Argument `json:"arguments,omitempty"`
+	ArgumentHint string           `json:"argument_hint,omitempty"`
+}
+
+func newSkillItem(sk skill.Info) skillItem {
+	return skillItem{
+		Name:         sk.Name,
+		Description:  sk.Description,
+		AllowedTools: sk.AllowedTools,
+		Version:      sk.Version,
+		Tags:         sk.Tags,
+		Keywords:     sk.Keywords,
+		WhenToUse:    sk.WhenToUse,
+		Arguments:    sk.Arguments,
+		ArgumentHint: skill.ArgumentHint(sk),
+	}
 }
 
 func (s *Server) handleListSkills(c *gin.Context) {
-	skills := skill.LoadInfos(s.config.RootDir)
+	skills := skill.For(s.config).Infos()
 	items := make([]skillItem, 0, len(skills))
 	for _, sk := range skills {
-		items = append(items, skillItem{Name: sk.Name, Description: sk.Description})
+		items = append(items, newSkillItem(sk))
 	}
 	c.JSON(http.StatusOK, gin.H{"skills": items})
 }
 
+const (
+	defaultPromptSkills = 5
+	defaultSkillMatches = 5
+	maxSkillMatches     = 20
+)
+
+type skillMatch struct {
+	skillItem
+	Score float64 `json:"score"`
+}
+
+// handleMatchSkills 按与 q 的相关度返回 skill，供插件在用户输入时推荐。
+func (s *Server) handleMatchSkills(c *gin.Context) {
+	q := c.Query("q")
+	if strings.TrimSpace(q) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	if len(q) > 4000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q too long"})
+		return
+	}
+	matches := skill.For(s.config).Search(q, queryInt(c, "limit", defaultSkillMatches, maxSkillMatches))
+	items := make([]skillMatch, 0, len(matches))
+	for _, m := range matches {
+		items = append(items, skillMatch{skillItem: newSkillItem(m.Info), Score: m.Score})
+	}
+	c.JSON(http.StatusOK, gin.H{"matches": items})
+}
+
+// queryInt 读取正整数查询参数，缺省或无效时返回 def，超过 max 时截断。
+func queryInt(c *gin.Context, key string, def, max int) int {
+	n, err := strconv.Atoi(c.Query(key))
+	if err != nil || n <= 0 {
+		return def
+	}
+	return min(n, max)
+}
+
+type commandItem struct {
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	Arguments    []skill.Argument `json:"arguments,omitempty"`
+	ArgumentHint string           `json:"argument_hint,omitempty"`
+	TakesArgs    bool             `json:"takes_args,omitempty"`
+}
+
+func (s *Server) handleListCommands(c *gin.Context) {
+	cmds := command.Load(s.config.RootDir)
+	items := make([]commandItem, 0, len(cmds))
+	for _, cmd := range cmds {
+		items = append(items, commandItem{
+			Name:         cmd.Name,
+			Description:  cmd.Description,
+			Arguments:    cmd.Arguments,
+			ArgumentHint: cmd.ArgumentHint,
+			TakesArgs:    cmd.TakesArgs,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"commands": items})
+}
+
+// handleRenderCommand 展开斜杠命令；args 可以是按名称传参的对象，也可以是自由格式字符串。
+func (s *Server) handleRenderCommand(c *gin.Context) {
+	var req struct {
+		Args interface{} `json:"args"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	cmd, ok := command.Get(s.config.RootDir, c.Param("name"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("command %q not found", c.Param("name"))})
+		return
+	}
+	var named map[string]string
+	var raw string
+	switch v := req.Args.(type) {
+	case nil:
+	case string:
+		raw = v
+	case map[string]interface{}:
+		named = make(map[string]string, len(v))
+		for k, val := range v {
+			if str, ok := val.(string); ok {
+				named[k] = str
+			} else {
+				named[k] = fmt.Sprint(val)
+			}
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "args must be an object or a string"})
+		return
+	}
+	text, err := command.Render(s.config.RootDir, cmd, named, raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"name": cmd.Name, "text": text})
+}
+
 func (s *Server) handleListFiles(c *gin.Context) {
 	q := strings.ToLower(c.Query("q"))
 	if len(q) > 200 {
@@ -215,16 +440,12 @@ func (s *Server) handleListFiles(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid root"})
 		return
 	}
-	skipDirs := map[string]bool{
-		".git": true, "node_modules": true, ".next": true,
-		"dist": true, "build": true, "vendor": true,
-	}
 	var files []string
 	filepath.WalkDir(s.config.RootDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
-		if d.IsDir() && skipDirs[d.Name()] {
+		if d.IsDir() && walk.SkipDir(d.Name()) {
 			return filepath.SkipDir
 		}
 		if !d.IsDir() {
@@ -2,13 +2,17 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/afumu/openlink/internal/tool"
 	"github.com/afumu/openlink/internal/types"
 )
 
@@ -161,6 +165,8 @@ func TestHandleListTools(t *testing.T) {
 }
 
 func TestHandlePrompt(t *testing.T) {
+	// /prompt 会合并用户主目录下的指令文件，隔离真实的 HOME
+	t.Setenv("HOME", t.TempDir())
 	s := testServer(t)
 
 	t.Run("missing init_prompt.txt returns 404", func(t *testing.T) {
@@ -188,6 +194,21 @@ func TestHandlePrompt(t *testing.T) {
 			t.Errorf("expected prompt content in response")
 		}
 	})
+
+	t.Run("project AGENTS.md is merged into the prompt", func(t *testing.T) {
+		os.WriteFile(filepath.Join(s.config.RootDir, "AGENTS.md"), []byte("Run go test before committing."), 0644)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/prompt", nil)
+		req.Header.Set("Authorization", "Bearer testtoken")
+		s.router.ServeHTTP(w, req)
+		body := w.Body.String()
+		if !strings.Contains(body, "### 来源：AGENTS.md\n\nRun go test before committing.") {
+			t.Errorf("expected AGENTS.md in prompt, got %q", body)
+		}
+		if strings.Index(body, "hello prompt") > strings.Index(body, "AGENTS.md") {
+			t.Error("instructions should follow the base prompt")
+		}
+	})
 }
 
 func TestCORSOptions(t *testing.T) {
@@ -199,3 +220,192 @@ func TestCORSOptions(t *testing.T) {
 		t.Errorf("expected 204, got %d", w.Code)
 	}
 }
+
+func TestQuestionEndpoints(t *testing.T) {
+	s := testServer(t)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer testtoken")
+		s.router.ServeHTTP(w, req)
+		return w
+	}
+
+	execDone := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		execDone <- do("POST", "/exec", `{"name":"question","session":"s1","args":{"question":"Deploy?","options":"[\"yes\",\"no\"]"}}`)
+	}()
+
+	var questions []tool.Question
+	for i := 0; i < 200 && len(questions) == 0; i++ {
+		time.Sleep(5 * time.Millisecond)
+		var body struct {
+			Questions []tool.Question `json:"questions"`
+		}
+		json.Unmarshal(do("GET", "/questions?session=s1", "").Body.Bytes(), &body)
+		questions = body.Questions
+	}
+	if len(questions) != 1 || questions[0].Question != "Deploy?" {
+		t.Fatalf("pending questions = %+v", questions)
+	}
+	id := questions[0].ID
+
+	if w := do("POST", "/questions/nope", `{"choices":["yes"]}`); w.Code != http.StatusNotFound {
+		t.Errorf("unknown id: expected 404, got %d", w.Code)
+	}
+	if w := do("POST", "/questions/"+id, `{"choices":["maybe"]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid choice: expected 400, got %d", w.Code)
+	}
+	if w := do("POST", "/questions/"+id, `{"choices":["yes"]}`); w.Code != http.StatusOK {
+		t.Fatalf("answer: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp types.ToolResponse
+	json.Unmarshal((<-execDone).Body.Bytes(), &resp)
+	if resp.Status != "success" || !strings.HasPrefix(resp.Output, "用户选择：yes") {
+		t.Errorf("exec response = %+v", resp)
+	}
+}
+
+func TestHandleListTodos(t *testing.T) {
+	s := testServer(t)
+	s.executor.Execute(context.Background(), &types.ToolRequest{
+		Name:    "todo_write",
+		Session: "s1",
+		Args:    map[string]interface{}{"todos": `["deploy"]`},
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/todos?session=s1", nil)
+	req.Header.Set("Authorization", "Bearer testtoken")
+	s.router.ServeHTTP(w, req)
+	var body struct {
+		Todos    []tool.Todo `json:"todos"`
+		Markdown string      `json:"markdown"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusOK || len(body.Todos) != 1 || body.Todos[0].Status != tool.TodoPending || !strings.Contains(body.Markdown, "deploy") {
+		t.Errorf("got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleListSkills(t *testing.T) {
+	s := testServer(t)
+	dir := filepath.Join(s.config.RootDir, ".skills", "deploy")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\ndescription: ship it\ntags: [ops]\nallowed-tools: exec_cmd\n---\n"), 0644)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/skills", nil)
+	req.Header.Set("Authorization", "Bearer testtoken")
+	s.router.ServeHTTP(w, req)
+	var body struct {
+		Skills []skillItem `json:"skills"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	for _, sk := range body.Skills {
+		if sk.Name == "deploy" {
+			if sk.Description != "ship it" || len(sk.Tags) != 1 || len(sk.AllowedTools) != 1 {
+				t.Errorf("got %+v", sk)
+			}
+			return
+		}
+	}
+	t.Errorf("deploy skill missing: %s", w.Body.String())
+}
+
+func TestHandleMatchSkills(t *testing.T) {
+	s := testServer(t)
+	for name, desc := range map[string]string{"deploy": "ship the service to production", "pdf": "fill PDF forms", "notes": "write release notes"} {
+		dir := filepath.Join(s.config.RootDir, ".skills", name)
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\ndescription: "+desc+"\n---\n"), 0644)
+	}
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Set("Authorization", "Bearer testtoken")
+		s.router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/skills/match"); w.Code != http.StatusBadRequest {
+		t.Errorf("missing q: status %d", w.Code)
+	}
+	w := get("/skills/match?q=deploy+to+production&limit=1")
+	var body struct {
+		Matches []skillMatch `json:"matches"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if len(body.Matches) != 1 || body.Matches[0].Name != "deploy" || body.Matches[0].Score <= 0 {
+		t.Errorf("got %s", w.Body.String())
+	}
+
+	os.MkdirAll(filepath.Join(s.config.RootDir, "prompts"), 0755)
+	os.WriteFile(filepath.Join(s.config.RootDir, "prompts", "init_prompt.txt"), []byte("hello prompt"), 0644)
+	w = get("/prompt?q=fill+a+pdf&k=1")
+	out := w.Body.String()
+	if !strings.Contains(out, "**pdf**") || strings.Contains(out, "**deploy**") || !strings.Contains(out, "skill_search") {
+		t.Errorf("got %s", out)
+	}
+	if !strings.Contains(out, "其他 skill（共 2 个") || !strings.Contains(out, "deploy") {
+		t.Errorf("other skills should be listed by name: %s", out)
+	}
+	out = get("/prompt?q=kubernetes&k=1").Body.String()
+	if !strings.Contains(out, "可用 skill（共 3 个") || !strings.Contains(out, "notes") {
+		t.Errorf("a query without matches should keep every skill name: %s", out)
+	}
+	if out := get("/prompt").Body.String(); !strings.Contains(out, "**deploy**") || !strings.Contains(out, "**pdf**") {
+		t.Errorf("prompt without q should list every skill: %s", out)
+	}
+}
+
+func TestCommandEndpoints(t *testing.T) {
+	s := testServer(t)
+	dir := filepath.Join(s.config.RootDir, ".openlink", "commands")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "review.md"), []byte("---\ndescription: Review code\narguments: [target]\n---\nReview {{target}}"), 0644)
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer testtoken")
+		req.Header.Set("Content-Type", "application/json")
+		s.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("GET", "/commands", "")
+	var list struct {
+		Commands []commandItem `json:"commands"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	found := false
+	for _, c := range list.Commands {
+		if c.Name == "review" {
+			found = c.Description == "Review code" && c.ArgumentHint == "[target]"
+		}
+	}
+	if !found {
+		t.Errorf("review command missing: %s", w.Body.String())
+	}
+
+	for body, want := range map[string]string{
+		`{"args":"main.go"}`:           "Review main.go",
+		`{"args":{"target":"server"}}`: "Review server",
+		``:                             "Review ",
+	} {
+		w = do("POST", "/commands/review/render", body)
+		var out struct{ Text string }
+		json.Unmarshal(w.Body.Bytes(), &out)
+		if w.Code != http.StatusOK || out.Text != strings.TrimSpace(want) {
+			t.Errorf("%s: %d %s", body, w.Code, w.Body.String())
+		}
+	}
+	if w = do("POST", "/commands/review/render", `{"args":{"nope":"x"}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown arg: status %d", w.Code)
+	}
+	if w = do("POST", "/commands/missing/render", `{}`); w.Code != http.StatusNotFound {
+		t.Errorf("missing command: status %d", w.Code)
+	}
+}
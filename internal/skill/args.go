@@ -0,0 +1,174 @@
+package skill
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var placeholderRe = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// ResolveArgs 计算 skill 参数的取值：named 为按名称传入的参数，raw 为自由格式的参数字符串。
+// 只传 raw 且 skill 声明了参数时，按空白（支持引号）依次分配给声明的参数，最后一个参数取剩余全部内容。
+// 未传入的参数使用 default；缺少必填参数，或 skill 声明了参数而传入了未声明的名称时返回错误。
+// 第二个返回值是替换 $ARGUMENTS 的文本。
+func ResolveArgs(info Info, named map[string]string, raw string) (map[string]string, string, error) {
+	return resolveArgs("skill", info, named, raw)
+}
+
+// resolveArgs 同 ResolveArgs，kind 用于错误信息，如 skill 或 command。
+func resolveArgs(kind string, info Info, named map[string]string, raw string) (map[string]string, string, error) {
+	values := map[string]string{}
+	for k, v := range named {
+		values[k] = v
+	}
+	raw = strings.TrimSpace(raw)
+	if len(named) == 0 && raw != "" && len(info.Arguments) > 0 {
+		fields := splitArgs(raw, len(info.Arguments))
+		for i, f := range fields {
+			values[info.Arguments[i].Name] = f
+		}
+	}
+
+	if len(info.Arguments) > 0 {
+		declared := map[string]bool{}
+		for _, a := range info.Arguments {
+			declared[a.Name] = true
+		}
+		var unknown []string
+		for k := range named {
+			if !declared[k] {
+				unknown = append(unknown, k)
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return nil, "", fmt.Errorf("%s %q has no argument %s (arguments: %s)", kind, info.Name, strings.Join(unknown, ", "), ArgumentHint(info))
+		}
+	}
+
+	var missing []string
+	for _, a := range info.Arguments {
+		if v, ok := values[a.Name]; ok && v != "" {
+			continue
+		}
+		switch {
+		case a.Default != "":
+			values[a.Name] = a.Default
+		case a.Required:
+			missing = append(missing, a.Name)
+		default:
+			values[a.Name] = ""
+		}
+	}
+	if len(missing) > 0 {
+		return nil, "", fmt.Errorf("%s %q: missing required argument %s (arguments: %s)", kind, info.Name, strings.Join(missing, ", "), ArgumentHint(info))
+	}
+
+	if raw == "" {
+		var parts []string
+		if len(info.Arguments) > 0 {
+			for _, a := range info.Arguments {
+				if v := values[a.Name]; v != "" {
+					parts = append(parts, v)
+				}
+			}
+		} else {
+			keys := make([]string, 0, len(named))
+			for k := range named {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				parts = append(parts, k+"="+named[k])
+			}
+		}
+		raw = strings.Join(parts, " ")
+	}
+	return values, raw, nil
+}
+
+// Render 将 SKILL.md 正文中的 $ARGUMENTS 与 {{name}} 占位符替换为参数取值，frontmatter 原样保留。
+// 没有取值的 {{name}} 保持不变。
+func Render(content string, info Info, named map[string]string, raw string) (string, error) {
+	return RenderAs("skill", content, info, named, raw)
+}
+
+// RenderAs 同 Render，kind 用于参数错误信息，供以相同格式书写的其他模板（如自定义命令）使用。
+func RenderAs(kind, content string, info Info, named map[string]string, raw string) (string, error) {
+	values, all, err := resolveArgs(kind, info, named, raw)
+	if err != nil {
+		return "", err
+	}
+	head, body := "", content
+	if _, rest, ok := splitFrontmatter(content); ok {
+		head, body = content[:len(content)-len(rest)], rest
+	}
+	body = strings.ReplaceAll(body, "$ARGUMENTS", all)
+	body = placeholderRe.ReplaceAllStringFunc(body, func(m string) string {
+		name := placeholderRe.FindStringSubmatch(m)[1]
+		if v, ok := values[name]; ok {
+			return v
+		}
+		return m
+	})
+	return head + body, nil
+}
+
+// UsesArguments 判断正文是否引用了参数（$ARGUMENTS 或 {{name}} 占位符）。
+func UsesArguments(body string) bool {
+	return strings.Contains(body, "$ARGUMENTS") || placeholderRe.MatchString(body)
+}
+
+// ArgumentHint 返回参数提示，如 "<env> [tag=latest]"：尖括号为必填，方括号为可选。
+// 未声明 arguments 时返回 frontmatter 中的 argument-hint。
+func ArgumentHint(info Info) string {
+	if len(info.Arguments) == 0 {
+		return info.ArgumentHint
+	}
+	parts := make([]string, 0, len(info.Arguments))
+	for _, a := range info.Arguments {
+		switch {
+		case a.Required:
+			parts = append(parts, "<"+a.Name+">")
+		case a.Default != "":
+			parts = append(parts, "["+a.Name+"="+a.Default+"]")
+		default:
+			parts = append(parts, "["+a.Name+"]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// splitArgs 按空白拆分参数字符串，引号内的空白不拆分；最多拆成 n 段，最后一段保留剩余原文。
+func splitArgs(s string, n int) []string {
+	var out []string
+	for len(out) < n-1 {
+		s = strings.TrimLeft(s, " \t\n")
+		if s == "" {
+			return out
+		}
+		var field string
+		if q := s[0]; q == '"' || q == '\'' {
+			if end := strings.IndexByte(s[1:], q); end >= 0 {
+				field, s = s[1:end+1], s[end+2:]
+				out = append(out, field)
+				continue
+			}
+		}
+		if i := strings.IndexAny(s, " \t\n"); i >= 0 {
+			field, s = s[:i], s[i:]
+		} else {
+			field, s = s, ""
+		}
+		out = append(out, field)
+	}
+	if s = strings.TrimSpace(s); s != "" {
+		if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+			s = s[1 : len(s)-1]
+		}
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package skill
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	info := Info{Name: "deploy", Arguments: []Argument{
+		{Name: "env", Required: true},
+		{Name: "tag", Default: "latest"},
+		{Name: "note"},
+	}}
+	content := "---\nname: deploy\ndescription: uses {{env}} literally\n---\nDeploy {{env}} at {{ tag }}.{{note}} All: $ARGUMENTS. Keep {{other}}.\n"
+
+	t.Run("named arguments", func(t *testing.T) {
+		out, err := Render(content, info, map[string]string{"env": "prod"}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "Deploy prod at latest. All: prod latest. Keep {{other}}.") {
+			t.Errorf("got %q", out)
+		}
+		if !strings.Contains(out, "description: uses {{env}} literally") {
+			t.Error("frontmatter must not be substituted")
+		}
+	})
+
+	t.Run("free-form string is split positionally", func(t *testing.T) {
+		out, err := Render(content, info, nil, `staging "v1.2 rc" ship it now`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "Deploy staging at v1.2 rc.ship it now All: staging \"v1.2 rc\" ship it now.") {
+			t.Errorf("got %q", out)
+		}
+	})
+
+	t.Run("missing required argument", func(t *testing.T) {
+		_, err := Render(content, info, map[string]string{"tag": "v2"}, "")
+		if err == nil || !strings.Contains(err.Error(), `skill "deploy": missing required argument env`) || !strings.Contains(err.Error(), "<env> [tag=latest] [note]") {
+			t.Errorf("got %v", err)
+		}
+	})
+
+	t.Run("unknown argument", func(t *testing.T) {
+		if _, err := Render(content, info, map[string]string{"env": "prod", "evn": "x"}, ""); err == nil {
+			t.Error("expected error for undeclared argument")
+		}
+	})
+
+	t.Run("skill without declared arguments", func(t *testing.T) {
+		out, err := Render("Run $ARGUMENTS for {{file}}", Info{Name: "x"}, nil, "main.go --fix")
+		if err != nil || out != "Run main.go --fix for {{file}}" {
+			t.Errorf("got %q, %v", out, err)
+		}
+		out, err = Render("Run $ARGUMENTS for {{file}}", Info{Name: "x"}, map[string]string{"file": "a.go"}, "")
+		if err != nil || out != "Run file=a.go for a.go" {
+			t.Errorf("got %q, %v", out, err)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package skill
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Argument 描述 skill 接受的一个参数。
+type Argument struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	Required    bool   `yaml:"required" json:"required,omitempty"`
+	Default     string `yaml:"default" json:"default,omitempty"`
+}
+
+// frontmatter 是 SKILL.md 开头 YAML 块中识别的字段。
+type frontmatter struct {
+	Name         string     `yaml:"name"`
+	Description  string     `yaml:"description"`
+	AllowedTools stringList `yaml:"allowed-tools"`
+	Version      rawString  `yaml:"version"`
+	Tags         stringList `yaml:"tags"`
+	Keywords     stringList `yaml:"keywords"`
+	Triggers     stringList `yaml:"triggers"`
+	WhenToUse    string     `yaml:"when-to-use"`
+	WhenToUseAlt string     `yaml:"when_to_use"`
+	Arguments    arguments  `yaml:"arguments"`
+	ArgumentHint string     `yaml:"argument-hint"`
+}
+
+// rawString 保留标量的原文，避免 version: 1.0 被当作数字解析成 "1"。
+type rawString string
+
+func (r *rawString) UnmarshalYAML(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'") {
+		var s string
+		if err := yaml.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		text = s
+	}
+	*r = rawString(text)
+	return nil
+}
+
+// stringList 兼容 YAML 列表和逗号分隔的字符串（如 "read_file, grep"）。
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*l = trimAll(list)
+		return nil
+	}
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return fmt.Errorf("expected a list or a comma-separated string")
+	}
+	*l = trimAll(strings.Split(s, ","))
+	return nil
+}
+
+func trimAll(list []string) []string {
+	out := make([]string, 0, len(list))
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// arguments 兼容参数名列表和参数对象列表两种写法。
+type arguments []Argument
+
+func (a *arguments) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var objs []Argument
+	if err := unmarshal(&objs); err == nil {
+		for i, arg := range objs {
+			if strings.TrimSpace(arg.Name) == "" {
+				return fmt.Errorf("arguments[%d]: name is required", i)
+			}
+		}
+		*a = objs
+		return nil
+	}
+	var names stringList
+	if err := unmarshal(&names); err != nil {
+		return fmt.Errorf("arguments: expected a list of names or {name, description, required, default} objects")
+	}
+	for _, n := range names {
+		*a = append(*a, Argument{Name: n})
+	}
+	return nil
+}
+
+// splitFrontmatter 拆出开头以单独一行 --- 包围的 YAML 块，没有时 ok 为 false。
+func splitFrontmatter(content string) (front, body string, ok bool) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	first, rest, found := strings.Cut(content, "\n")
+	if !found || strings.TrimSpace(first) != "---" {
+		return "", content, false
+	}
+	offset := 0
+	for {
+		line, next, more := strings.Cut(rest[offset:], "\n")
+		if strings.TrimSpace(line) == "---" {
+			return rest[:offset], next, true
+		}
+		if !more {
+			return "", content, false
+		}
+		offset += len(line) + 1
+	}
+}
+
+// parseFrontmatter 解析 YAML 块；块不存在时返回零值。
+func parseFrontmatter(content string) (frontmatter, error) {
+	var fm frontmatter
+	front, _, ok := splitFrontmatter(content)
+	if !ok || strings.TrimSpace(front) == "" {
+		return fm, nil
+	}
+	if err := yaml.Unmarshal([]byte(front), &fm); err != nil {
+		return fm, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	if fm.WhenToUse == "" {
+		fm.WhenToUse = fm.WhenToUseAlt
+	}
+	fm.Keywords = append(fm.Keywords, fm.Triggers...)
+	return fm, nil
+}
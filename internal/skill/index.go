@@ -0,0 +1,153 @@
+package skill
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stamp 是被监视路径的状态，路径不存在时 exists 为 false。
+type stamp struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func statStamp(path string) stamp {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return stamp{}
+	}
+	return stamp{exists: true, modTime: fi.ModTime(), size: fi.Size()}
+}
+
+// Index 是 skill 目录的内存索引。每次查询只对上次扫描涉及的目录、子目录和 SKILL.md 做 stat，
+// 任何一个的修改时间或大小变化（包括新增、删除）才会重新扫描，因此热路径上不再读取和解析文件。
+type Index struct {
+	dirs []string
+
+	mu      sync.Mutex
+	scanned bool
+	infos   []Info
+	all     []Info
+	watched map[string]stamp
+	scans   int
+}
+
+// NewIndex 创建按 dirs 顺序扫描的索引。
+func NewIndex(dirs []string) *Index {
+	return &Index{dirs: dirs}
+}
+
+var indexes sync.Map // 以 \x00 连接的 dirs -> *Index
+
+// IndexFor 返回按 dirs 顺序扫描的共享索引，目录列表相同时复用同一个索引。
+func IndexFor(dirs []string) *Index {
+	key := strings.Join(dirs, "\x00")
+	if ix, ok := indexes.Load(key); ok {
+		return ix.(*Index)
+	}
+	ix, _ := indexes.LoadOrStore(key, NewIndex(dirs))
+	return ix.(*Index)
+}
+
+// Infos 返回全部 skill，目录有变化时先重新扫描。
+func (ix *Index) Infos() []Info {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if !ix.scanned || ix.changed() {
+		ix.scan()
+	}
+	return append([]Info(nil), ix.infos...)
+}
+
+// All 返回扫描到的全部 skill（含被覆盖的），按优先级顺序排列，被覆盖的设置了 ShadowedBy。
+func (ix *Index) All() []Info {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if !ix.scanned || ix.changed() {
+		ix.scan()
+	}
+	return append([]Info(nil), ix.all...)
+}
+
+// Get 按名称（大小写不敏感）查找 skill。
+func (ix *Index) Get(name string) (Info, bool) {
+	if strings.ContainsAny(name, "/\\") || strings.Contains(name, "..") {
+		return Info{}, false
+	}
+	for _, info := range ix.Infos() {
+		if strings.EqualFold(info.Name, name) {
+			return info, true
+		}
+	}
+	return Info{}, false
+}
+
+func (ix *Index) changed() bool {
+	for path, st := range ix.watched {
+		if statStamp(path) != st {
+			return true
+		}
+	}
+	return false
+}
+
+func (ix *Index) scan() {
+	ix.scans++
+	ix.watched = make(map[string]stamp)
+	watch := func(path string) { ix.watched[path] = statStamp(path) }
+
+	// 同名 skill 以优先级更高（先扫描到）的为准，名称比较不区分大小写
+	winners := map[string]Info{}
+	ix.infos, ix.all = nil, nil
+	for _, dir := range ix.dirs {
+		watch(dir)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		log.Printf("[Skill] 扫描目录: %s", dir)
+		for _, entry := range entries {
+			// 跟随软链接：用 os.Stat 而非 entry.Type()
+			subPath := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(subPath)
+			if err != nil || !info.IsDir() {
+				continue
+			}
+			watch(subPath)
+			skillFile := findSkillMd(subPath)
+			if skillFile == "" {
+				continue
+			}
+			watch(skillFile)
+			data, err := os.ReadFile(skillFile)
+			if err != nil {
+				continue
+			}
+			sk, err := parse(skillFile, string(data))
+			if err != nil {
+				log.Printf("[Skill] %s: %v", skillFile, err)
+			}
+			sk.Dir = subPath
+			sk.Location = skillFile
+			sk.Source = dir
+			sk.Err = err
+			key := strings.ToLower(sk.Name)
+			if winner, exists := winners[key]; exists {
+				sk.ShadowedBy = winner.Location
+				log.Printf("[Skill] %s 被 %s 覆盖", skillFile, winner.Location)
+			} else {
+				winners[key] = sk
+				ix.infos = append(ix.infos, sk)
+			}
+			ix.all = append(ix.all, sk)
+		}
+	}
+
+	log.Printf("[Skill] 共加载 %d 个 skill", len(ix.infos))
+	ix.scanned = true
+}
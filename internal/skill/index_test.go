@@ -0,0 +1,145 @@
+package skill
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/afumu/openlink/internal/types"
+	"time"
+)
+
+func writeSkill(t *testing.T, dir, name, description string) {
+	t.Helper()
+	sub := filepath.Join(dir, name)
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nname: " + name + "\ndescription: " + description + "\n---\n"
+	if err := os.WriteFile(filepath.Join(sub, "SKILL.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndexCachesUntilChanged(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	later := filepath.Join(root, "later") // 尚不存在的目录
+	writeSkill(t, project, "alpha", "first")
+	ix := NewIndex([]string{project, later})
+
+	if got := ix.Infos(); len(got) != 1 || got[0].Name != "alpha" {
+		t.Fatalf("got %+v", got)
+	}
+	ix.Infos()
+	ix.Get("alpha")
+	if ix.scans != 1 {
+		t.Fatalf("unchanged directories rescanned: %d scans", ix.scans)
+	}
+
+	// 修改已有 SKILL.md
+	writeSkill(t, project, "alpha", "first, edited")
+	if info, _ := ix.Get("alpha"); info.Description != "first, edited" {
+		t.Errorf("edit not picked up: %+v", info)
+	}
+
+	// 新增 skill 目录
+	writeSkill(t, project, "beta", "second")
+	if _, ok := ix.Get("beta"); !ok {
+		t.Error("new skill not picked up")
+	}
+
+	// 原本不存在的目录被创建
+	writeSkill(t, later, "gamma", "third")
+	if _, ok := ix.Get("gamma"); !ok {
+		t.Error("skill in newly created directory not picked up")
+	}
+
+	// 删除 skill
+	os.RemoveAll(filepath.Join(project, "beta"))
+	if _, ok := ix.Get("beta"); ok {
+		t.Error("removed skill still listed")
+	}
+
+	scans := ix.scans
+	ix.Infos()
+	if ix.scans != scans {
+		t.Error("rescanned without changes")
+	}
+}
+
+func TestIndexDetectsSameSizeEdit(t *testing.T) {
+	dir := t.TempDir()
+	writeSkill(t, dir, "alpha", "aaaa")
+	ix := NewIndex([]string{dir})
+	ix.Infos()
+
+	writeSkill(t, dir, "alpha", "bbbb")
+	// 内容长度不变时依赖修改时间，显式推后以免受文件系统时间精度影响
+	future := time.Now().Add(2 * time.Second)
+	os.Chtimes(filepath.Join(dir, "alpha", "SKILL.md"), future, future)
+	if info, _ := ix.Get("alpha"); info.Description != "bbbb" {
+		t.Errorf("got %+v", info)
+	}
+}
+
+func TestIndexForIsShared(t *testing.T) {
+	root := t.TempDir()
+	if IndexFor(SkillDirs(root)) != IndexFor(SkillDirs(root)) {
+		t.Error("expected the same index for the same dirs")
+	}
+	cfg := &types.Config{RootDir: root}
+	before := For(cfg)
+	cfg.Project.SkillDirs = []string{"tools/skills"}
+	if For(cfg) == before {
+		t.Error("expected a new index when skill_dirs changes")
+	}
+}
+
+func TestIndexPrecedence(t *testing.T) {
+	root := t.TempDir()
+	high, low := filepath.Join(root, "high"), filepath.Join(root, "low")
+	writeSkill(t, high, "deploy", "project version")
+	writeSkill(t, low, "deploy", "global version")
+	writeSkill(t, low, "other", "")
+	ix := NewIndex([]string{high, low})
+
+	info, ok := ix.Get("deploy")
+	if !ok || info.Description != "project version" || info.Source != high {
+		t.Fatalf("first directory must win, got %+v", info)
+	}
+	all := ix.All()
+	if len(all) != 3 || all[1].Name != "deploy" || all[1].ShadowedBy != info.Location {
+		t.Fatalf("all = %+v", all)
+	}
+
+	warnings := Lint(all)
+	var shadow, missing bool
+	for _, w := range warnings {
+		shadow = shadow || (w.Location == all[1].Location && strings.Contains(w.Message, "覆盖"))
+		missing = missing || (strings.Contains(w.Location, "other") && strings.Contains(w.Message, "description"))
+	}
+	if !shadow || !missing || len(warnings) != 2 {
+		t.Errorf("warnings = %v", warnings)
+	}
+}
+
+func TestDirsFromProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	if got := Dirs(root, types.ProjectConfig{}); strings.Join(got, "|") != strings.Join(SkillDirs(root), "|") {
+		t.Errorf("default dirs = %v", got)
+	}
+	// 运行期间写入的配置文件不影响已加载的配置
+	os.MkdirAll(filepath.Join(root, ".openlink"), 0755)
+	os.WriteFile(filepath.Join(root, ".openlink", "config.json"), []byte(`{"skill_dirs":["/tmp"]}`), 0644)
+	if got := Dirs(root, types.ProjectConfig{}); got[0] != filepath.Join(root, ".skills") {
+		t.Errorf("config file should not be re-read: %v", got)
+	}
+	home, _ := os.UserHomeDir()
+	got := Dirs(root, types.ProjectConfig{SkillDirs: []string{"tools/skills", "~/.claude/skills", "/opt/skills"}})
+	want := []string{filepath.Join(root, "tools", "skills"), filepath.Join(home, ".claude", "skills"), filepath.Clean("/opt/skills")}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,561 @@
+package skill
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LockFile 是安装目录下记录已安装 skill 来源与版本的文件。
+const LockFile = "skills-lock.json"
+
+const (
+	maxInstallBytes = 50 << 20
+	maxInstallFiles = 2000
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// 安装来源类型。
+const (
+	SourceDir = "dir"
+	SourceZip = "zip"
+	SourceTar = "tar"
+	SourceGit = "git"
+)
+
+// LockEntry 是锁文件中的一条安装记录。
+type LockEntry struct {
+	Source      string    `json:"source"`
+	Type        string    `json:"type"`
+	Ref         string    `json:"ref,omitempty"`
+	Path        string    `json:"path,omitempty"`
+	Commit      string    `json:"commit,omitempty"`
+	Version     string    `json:"version,omitempty"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// Lock 是锁文件内容。
+type Lock struct {
+	Skills map[string]LockEntry `json:"skills"`
+}
+
+// UserInstallDir 返回用户级安装目录 ~/.openlink/skills。
+func UserInstallDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".openlink", "skills"), nil
+}
+
+// ProjectInstallDir 返回项目级安装目录 <rootDir>/.openlink/skills。
+func ProjectInstallDir(rootDir string) string {
+	return filepath.Join(rootDir, ".openlink", "skills")
+}
+
+// ReadLock 读取安装目录的锁文件，不存在时返回空锁。
+func ReadLock(dir string) (Lock, error) {
+	lock := Lock{Skills: map[string]LockEntry{}}
+	data, err := os.ReadFile(filepath.Join(dir, LockFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return lock, err
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return lock, fmt.Errorf("invalid %s: %w", filepath.Join(dir, LockFile), err)
+	}
+	if lock.Skills == nil {
+		lock.Skills = map[string]LockEntry{}
+	}
+	return lock, nil
+}
+
+func writeLock(dir string, lock Lock) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	// 临时文件名唯一，避免同时安装到同一目录时互相覆盖
+	tmp, err := os.CreateTemp(dir, LockFile+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, LockFile))
+}
+
+// InstallOptions 是安装参数。
+type InstallOptions struct {
+	// Ref 是 git 来源的分支或标签，也可以写在 URL 末尾的 #ref 中
+	Ref string
+	// Path 是来源中 skill 所在的子目录，用于一个仓库或压缩包包含多个 skill 的情况
+	Path string
+	// Force 为 true 时覆盖同名的已安装 skill
+	Force bool
+}
+
+// SourceType 根据来源字符串判断类型：git 地址、zip/tar 压缩包或本地目录。
+func SourceType(source string) string {
+	lower := strings.ToLower(strings.SplitN(source, "#", 2)[0])
+	switch {
+	case strings.HasPrefix(lower, "git@"), strings.HasPrefix(lower, "git://"), strings.HasPrefix(lower, "ssh://"), strings.HasPrefix(lower, "file://"),
+		strings.HasPrefix(lower, "http://"), strings.HasPrefix(lower, "https://"), strings.HasSuffix(lower, ".git"):
+		return SourceGit
+	case strings.HasSuffix(lower, ".zip"):
+		return SourceZip
+	case strings.HasSuffix(lower, ".tar"), strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return SourceTar
+	}
+	return SourceDir
+}
+
+// Install 将 source 中的 skill 安装到 dir/<name>，并在锁文件中记录来源与版本。
+func Install(dir, source string, opts InstallOptions) (Info, error) {
+	entry := LockEntry{Source: source, Type: SourceType(source), Ref: opts.Ref, Path: opts.Path}
+	if entry.Type == SourceGit {
+		if base, ref, ok := strings.Cut(source, "#"); ok {
+			entry.Source, entry.Ref = base, ref
+		}
+	} else if abs, err := filepath.Abs(source); err == nil {
+		entry.Source = abs
+	}
+	return install(dir, entry, opts.Force, "")
+}
+
+// install 安装 entry 描述的 skill。force 为 true 时覆盖同名的已安装 skill；
+// replaces 非空时只允许覆盖名为 replaces 的 skill，避免来源改名后覆盖另一个已安装的 skill。
+func install(dir string, entry LockEntry, force bool, replaces string) (Info, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Info{}, err
+	}
+	// 暂存目录与目标在同一目录下，保证最后一步可以原子地重命名
+	staging, err := os.MkdirTemp(dir, ".install-")
+	if err != nil {
+		return Info{}, err
+	}
+	defer os.RemoveAll(staging)
+
+	switch entry.Type {
+	case SourceDir:
+		err = copyTree(entry.Source, staging)
+	case SourceZip:
+		err = extractZip(entry.Source, staging)
+	case SourceTar:
+		err = extractTar(entry.Source, staging)
+	case SourceGit:
+		entry.Commit, err = gitClone(entry.Source, entry.Ref, staging)
+	default:
+		err = fmt.Errorf("unknown source type %q", entry.Type)
+	}
+	if err != nil {
+		return Info{}, err
+	}
+
+	root, err := skillRoot(staging, entry.Path)
+	if err != nil {
+		return Info{}, err
+	}
+	info, err := validateDir(root)
+	if err != nil {
+		return Info{}, err
+	}
+
+	dest := filepath.Join(dir, info.Name)
+	var old string
+	if _, err := os.Stat(dest); err == nil {
+		if !force {
+			return Info{}, fmt.Errorf("skill %q is already installed at %s (use update or --force)", info.Name, dest)
+		}
+		if replaces != "" && info.Name != replaces {
+			return Info{}, fmt.Errorf("source now provides skill %q, which is already installed at %s", info.Name, dest)
+		}
+		// 原安装移入隐藏的临时目录，不与其他 skill 的目录名冲突
+		backup, err := os.MkdirTemp(dir, ".backup-")
+		if err != nil {
+			return Info{}, err
+		}
+		defer os.RemoveAll(backup)
+		old = filepath.Join(backup, info.Name)
+		if err := os.Rename(dest, old); err != nil {
+			return Info{}, err
+		}
+	}
+	if err := os.Rename(root, dest); err != nil {
+		// 新版本未能就位时恢复原来的安装
+		if old != "" {
+			os.Rename(old, dest)
+		}
+		return Info{}, err
+	}
+
+	lock, err := ReadLock(dir)
+	if err != nil {
+		return Info{}, err
+	}
+	entry.Version = info.Version
+	entry.InstalledAt = time.Now().UTC().Truncate(time.Second)
+	lock.Skills[info.Name] = entry
+	if err := writeLock(dir, lock); err != nil {
+		return Info{}, err
+	}
+	info.Dir = dest
+	info.Location = filepath.Join(dest, filepath.Base(info.Location))
+	info.Source = dir
+	return info, nil
+}
+
+// Update 按锁文件中记录的来源重新安装 name；name 为空时更新全部。返回更新后的 skill。
+func Update(dir, name string) ([]Info, error) {
+	lock, err := ReadLock(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{name}
+	if name == "" {
+		names = names[:0]
+		for n := range lock.Skills {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+	}
+	var updated []Info
+	for _, n := range names {
+		entry, ok := lock.Skills[n]
+		if !ok {
+			return updated, fmt.Errorf("skill %q was not installed with openlink skill install", n)
+		}
+		info, err := install(dir, entry, true, n)
+		if err != nil {
+			return updated, fmt.Errorf("update %s: %w", n, err)
+		}
+		if info.Name != n {
+			// 来源中的 skill 改了名：移除旧目录与旧记录
+			if err := Remove(dir, n); err != nil {
+				return updated, err
+			}
+		}
+		updated = append(updated, info)
+	}
+	return updated, nil
+}
+
+// Remove 删除安装目录下的 skill 及其锁文件记录。
+func Remove(dir, name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid skill name %q", name)
+	}
+	lock, err := ReadLock(dir)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, name)
+	_, statErr := os.Stat(path)
+	if _, ok := lock.Skills[name]; !ok && statErr != nil {
+		return fmt.Errorf("skill %q is not installed in %s", name, dir)
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	delete(lock.Skills, name)
+	return writeLock(dir, lock)
+}
+
+// Validate 检查 skill 目录（或其中的 SKILL.md）能否被安装，返回解析出的信息和警告。
+func Validate(path string) (Info, []Warning, error) {
+	if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
+		path = filepath.Dir(path)
+	}
+	info, err := validateDir(path)
+	if err != nil {
+		return info, nil, err
+	}
+	warnings := Lint([]Info{info})
+	for _, tool := range info.AllowedTools {
+		if !validName.MatchString(tool) {
+			warnings = append(warnings, Warning{info.Location, fmt.Sprintf("allowed-tools 中的 %q 不是有效的工具名", tool)})
+		}
+	}
+	return info, warnings, nil
+}
+
+// validateDir 解析目录下的 SKILL.md，frontmatter 无效或名称不合法时返回错误。
+func validateDir(dir string) (Info, error) {
+	skillFile := findSkillMd(dir)
+	if skillFile == "" {
+		return Info{}, fmt.Errorf("no SKILL.md found in %s", dir)
+	}
+	data, err := os.ReadFile(skillFile)
+	if err != nil {
+		return Info{}, err
+	}
+	info, err := parse(skillFile, string(data))
+	if err != nil {
+		return info, fmt.Errorf("%s: %w", skillFile, err)
+	}
+	if !validName.MatchString(info.Name) {
+		return info, fmt.Errorf("%s: invalid skill name %q (letters, digits, '.', '_' and '-' only)", skillFile, info.Name)
+	}
+	info.Dir, info.Location = dir, skillFile
+	return info, nil
+}
+
+// skillRoot 在解包结果中定位 skill 目录：指定 sub 时使用该子目录，否则是根目录或唯一的顶层目录。
+func skillRoot(staging, sub string) (string, error) {
+	if sub != "" {
+		rel, err := safeRel(sub)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(staging, rel), nil
+	}
+	if findSkillMd(staging) != "" {
+		return staging, nil
+	}
+	entries, err := os.ReadDir(staging)
+	if err != nil {
+		return "", err
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() && findSkillMd(filepath.Join(staging, e.Name())) != "" {
+			dirs = append(dirs, e.Name())
+		}
+	}
+	switch len(dirs) {
+	case 1:
+		return filepath.Join(staging, dirs[0]), nil
+	case 0:
+		return "", errors.New("no SKILL.md found in source")
+	}
+	return "", fmt.Errorf("source contains several skills (%s); choose one with --path", strings.Join(dirs, ", "))
+}
+
+// safeRel 校验压缩包条目或子目录路径：必须是不含 .. 的相对路径。
+func safeRel(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if name == "" || strings.HasPrefix(name, "/") || filepath.VolumeName(name) != "" || strings.Contains(name, ":") {
+		return "", fmt.Errorf("unsafe path %q", name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("unsafe path %q: path traversal", name)
+		}
+	}
+	return filepath.FromSlash(strings.TrimSuffix(name, "/")), nil
+}
+
+// budget 限制解包的文件数与总大小。
+type budget struct{ files, bytes int64 }
+
+func (b *budget) add(size int64) error {
+	b.files++
+	b.bytes += size
+	if b.files > maxInstallFiles {
+		return fmt.Errorf("source has more than %d files", maxInstallFiles)
+	}
+	if b.bytes > maxInstallBytes {
+		return fmt.Errorf("source is larger than %d MiB", maxInstallBytes>>20)
+	}
+	return nil
+}
+
+func writeEntry(dest string, r io.Reader, mode os.FileMode, b *budget) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	// 多读一个字节以发现超出上限的条目
+	n, err := io.Copy(f, io.LimitReader(r, maxInstallBytes-b.bytes+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return b.add(n)
+}
+
+func extractZip(archive, staging string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	var b budget
+	for _, f := range zr.File {
+		rel, err := safeRel(f.Name)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(staging, rel)
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(dest, 0755); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = writeEntry(dest, rc, mode, &b)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported entry %q in archive (only files and directories are allowed)", f.Name)
+		}
+	}
+	return nil
+}
+
+func extractTar(archive, staging string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	var b budget
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Name == "./" || hdr.Name == "." {
+			continue
+		}
+		rel, err := safeRel(strings.TrimPrefix(hdr.Name, "./"))
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(staging, rel)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dest, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeEntry(dest, tr, hdr.FileInfo().Mode(), &b); err != nil {
+				return err
+			}
+		case tar.TypeXGlobalHeader:
+		default:
+			return fmt.Errorf("unsupported entry %q in archive (only files and directories are allowed)", hdr.Name)
+		}
+	}
+}
+
+// copyTree 复制本地 skill 目录，跳过 .git；不复制符号链接，避免把目录外的文件带进来。
+func copyTree(src, staging string) error {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory, zip or tar archive", src)
+	}
+	var b budget
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		if rel == "." {
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		dest := filepath.Join(staging, rel)
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(dest, 0755)
+		case d.Type().IsRegular():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return writeEntry(dest, f, info.Mode(), &b)
+		}
+		return fmt.Errorf("unsupported file %s (symlinks and special files are not copied)", path)
+	})
+}
+
+// gitClone 浅克隆仓库，返回检出的提交。克隆完成后删除 .git 目录。
+func gitClone(url, ref, staging string) (string, error) {
+	args := []string{"clone", "--depth", "1", "--quiet"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "--", url, staging)
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git clone %s: %v: %s", url, err, strings.TrimSpace(string(out)))
+	}
+	out, err := exec.Command("git", "-C", staging, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	if err := os.RemoveAll(filepath.Join(staging, ".git")); err != nil {
+		return "", err
+	}
+	// 仓库中的符号链接可能指向目录外，与压缩包一样拒绝
+	err = filepath.WalkDir(staging, func(path string, d os.DirEntry, err error) error {
+		if err == nil && d.Type()&os.ModeSymlink != 0 {
+			return fmt.Errorf("unsupported symlink %s in repository", strings.TrimPrefix(path, staging+string(filepath.Separator)))
+		}
+		return err
+	})
+	return strings.TrimSpace(string(out)), err
+}
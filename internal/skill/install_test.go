@@ -0,0 +1,298 @@
+package skill
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	f.Close()
+}
+
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	f.Close()
+}
+
+func TestSourceType(t *testing.T) {
+	cases := map[string]string{
+		"./skills/deploy":                       SourceDir,
+		"deploy.zip":                            SourceZip,
+		"deploy.tar.gz":                         SourceTar,
+		"deploy.tgz":                            SourceTar,
+		"https://github.com/acme/skills.git#v1": SourceGit,
+		"git@github.com:acme/skills.git":        SourceGit,
+		"https://example.com/acme/deploy-skill": SourceGit,
+	}
+	for src, want := range cases {
+		if got := SourceType(src); got != want {
+			t.Errorf("SourceType(%q) = %s, want %s", src, got, want)
+		}
+	}
+}
+
+func TestInstallFromDirectoryAndUpdate(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "deploy-src")
+	writeSkill(t, filepath.Dir(src), "deploy-src", "v1")
+	os.WriteFile(filepath.Join(src, "script.sh"), []byte("echo hi\n"), 0755)
+	target := t.TempDir()
+
+	info, err := Install(target, src, InstallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Dir != filepath.Join(target, "deploy-src") {
+		t.Errorf("installed to %s", info.Dir)
+	}
+	if _, err := os.Stat(filepath.Join(info.Dir, "script.sh")); err != nil {
+		t.Error("resource file not copied")
+	}
+	lock, _ := ReadLock(target)
+	if e := lock.Skills["deploy-src"]; e.Type != SourceDir || e.Source != src || e.InstalledAt.IsZero() {
+		t.Errorf("lock entry = %+v", e)
+	}
+
+	if _, err := Install(target, src, InstallOptions{}); err == nil || !strings.Contains(err.Error(), "already installed") {
+		t.Errorf("reinstall without --force: %v", err)
+	}
+
+	writeSkill(t, filepath.Dir(src), "deploy-src", "v2")
+	if _, err := Update(target, ""); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := NewIndex([]string{target}).Get("deploy-src"); info.Description != "v2" {
+		t.Errorf("update not applied: %+v", info)
+	}
+
+	if err := Remove(target, "deploy-src"); err != nil {
+		t.Fatal(err)
+	}
+	lock, _ = ReadLock(target)
+	if _, err := os.Stat(filepath.Join(target, "deploy-src")); err == nil || len(lock.Skills) != 0 {
+		t.Error("skill not removed")
+	}
+	if err := Remove(target, "../etc"); err == nil {
+		t.Error("expected invalid name error")
+	}
+}
+
+func TestForceInstallKeepsOtherSkills(t *testing.T) {
+	srcs := t.TempDir()
+	writeSkill(t, srcs, "foo", "v1")
+	writeSkill(t, srcs, "foo.old", "unrelated")
+	target := t.TempDir()
+	for _, name := range []string{"foo", "foo.old"} {
+		if _, err := Install(target, filepath.Join(srcs, name), InstallOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeSkill(t, srcs, "foo", "v2")
+	if _, err := Install(target, filepath.Join(srcs, "foo"), InstallOptions{Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	ix := NewIndex([]string{target})
+	if info, _ := ix.Get("foo.old"); info.Description != "unrelated" {
+		t.Errorf("forced install of foo touched foo.old: %+v", info)
+	}
+	if info, _ := ix.Get("foo"); info.Description != "v2" {
+		t.Errorf("foo not updated: %+v", info)
+	}
+	entries, _ := os.ReadDir(target)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".backup-") {
+			t.Errorf("backup directory %s left behind", e.Name())
+		}
+	}
+}
+
+func TestUpdateRenamedSkillConflict(t *testing.T) {
+	srcs := t.TempDir()
+	writeSkill(t, srcs, "a", "from a")
+	writeSkill(t, srcs, "b", "other b")
+	target := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		if _, err := Install(target, filepath.Join(srcs, name), InstallOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a 的来源改名为 b，与另一个来源安装的 b 冲突
+	os.WriteFile(filepath.Join(srcs, "a", "SKILL.md"), []byte("---\nname: b\ndescription: renamed a\n---\n"), 0644)
+	if _, err := Update(target, "a"); err == nil || !strings.Contains(err.Error(), "already installed") {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+	ix := NewIndex([]string{target})
+	if info, _ := ix.Get("b"); info.Description != "other b" {
+		t.Errorf("update overwrote b: %+v", info)
+	}
+	if _, ok := ix.Get("a"); !ok {
+		t.Error("a should stay installed after a failed update")
+	}
+	lock, _ := ReadLock(target)
+	if lock.Skills["b"].Source != filepath.Join(srcs, "b") {
+		t.Errorf("lock entry for b was taken over: %+v", lock.Skills["b"])
+	}
+}
+
+func TestInstallArchives(t *testing.T) {
+	dir := t.TempDir()
+	skillMd := "---\nname: lint\ndescription: run linters\nversion: 0.3.0\n---\nbody\n"
+
+	t.Run("zip with top-level folder", func(t *testing.T) {
+		archive := filepath.Join(dir, "lint.zip")
+		writeZip(t, archive, map[string]string{"lint-main/SKILL.md": skillMd, "lint-main/ref/rules.md": "rules"})
+		target := t.TempDir()
+		info, err := Install(target, archive, InstallOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Name != "lint" || info.Version != "0.3.0" {
+			t.Errorf("got %+v", info)
+		}
+		if _, err := os.Stat(filepath.Join(target, "lint", "ref", "rules.md")); err != nil {
+			t.Error("nested file missing")
+		}
+		lock, _ := ReadLock(target)
+		if lock.Skills["lint"].Version != "0.3.0" || lock.Skills["lint"].Type != SourceZip {
+			t.Errorf("lock = %+v", lock.Skills)
+		}
+	})
+
+	t.Run("tar.gz", func(t *testing.T) {
+		archive := filepath.Join(dir, "lint.tar.gz")
+		writeTarGz(t, archive, map[string]string{"./SKILL.md": skillMd})
+		if _, err := Install(t.TempDir(), archive, InstallOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("path traversal is refused", func(t *testing.T) {
+		for name, write := range map[string]func(*testing.T, string, map[string]string){"evil.zip": writeZip, "evil.tar.gz": writeTarGz} {
+			archive := filepath.Join(dir, name)
+			write(t, archive, map[string]string{"SKILL.md": skillMd, "../../escaped.txt": "x"})
+			target := filepath.Join(t.TempDir(), "skills")
+			_, err := Install(target, archive, InstallOptions{})
+			if err == nil || !strings.Contains(err.Error(), "traversal") {
+				t.Errorf("%s: expected traversal error, got %v", name, err)
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(target), "escaped.txt")); err == nil {
+				t.Errorf("%s: file escaped the install directory", name)
+			}
+			if entries, _ := os.ReadDir(target); len(entries) != 0 {
+				t.Errorf("%s: staging left behind: %v", name, entries)
+			}
+		}
+	})
+
+	t.Run("several skills need --path", func(t *testing.T) {
+		archive := filepath.Join(dir, "many.zip")
+		writeZip(t, archive, map[string]string{"a/SKILL.md": "---\nname: a\n---\n", "b/SKILL.md": "---\nname: b\n---\n"})
+		if _, err := Install(t.TempDir(), archive, InstallOptions{}); err == nil {
+			t.Error("expected error")
+		}
+		if info, err := Install(t.TempDir(), archive, InstallOptions{Path: "b"}); err != nil || info.Name != "b" {
+			t.Errorf("got %+v, %v", info, err)
+		}
+	})
+}
+
+func TestInstallFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	writeSkill(t, repo, "gitskill", "from git")
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-qm", "init")
+
+	target := t.TempDir()
+	info, err := Install(target, "file://"+repo, InstallOptions{Path: "gitskill"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(info.Dir, ".git")); err == nil {
+		t.Error(".git should not be installed")
+	}
+	lock, _ := ReadLock(target)
+	if e := lock.Skills["gitskill"]; e.Type != SourceGit || len(e.Commit) != 40 || e.Path != "gitskill" {
+		t.Errorf("lock entry = %+v", e)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	writeSkill(t, dir, "ok", "")
+	info, warnings, err := Validate(filepath.Join(dir, "ok"))
+	if err != nil || info.Name != "ok" || len(warnings) != 1 {
+		t.Errorf("got %+v %v %v", info, warnings, err)
+	}
+
+	bad := filepath.Join(dir, "bad")
+	os.MkdirAll(bad, 0755)
+	os.WriteFile(filepath.Join(bad, "SKILL.md"), []byte("---\nname: ../evil\n---\n"), 0644)
+	if _, _, err := Validate(bad); err == nil {
+		t.Error("expected invalid name error")
+	}
+}
+
+func TestWriteLockConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lock := Lock{Skills: map[string]LockEntry{fmt.Sprint("s", i): {Source: "x", Type: "dir"}}}
+			if err := writeLock(dir, lock); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if _, err := ReadLock(dir); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected only the lock file, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,28 @@
+package skill
+
+import "fmt"
+
+// Warning 是 Lint 发现的问题。
+type Warning struct {
+	Location string
+	Message  string
+}
+
+func (w Warning) String() string { return w.Location + ": " + w.Message }
+
+// Lint 检查 All 返回的 skill：frontmatter 解析错误、缺少 description、同名 skill 互相覆盖。
+func Lint(all []Info) []Warning {
+	var out []Warning
+	for _, sk := range all {
+		if sk.Err != nil {
+			out = append(out, Warning{sk.Location, sk.Err.Error()})
+		}
+		if sk.Description == "" {
+			out = append(out, Warning{sk.Location, fmt.Sprintf("skill %q 缺少 description", sk.Name)})
+		}
+		if sk.ShadowedBy != "" {
+			out = append(out, Warning{sk.Location, fmt.Sprintf("skill %q 与 %s 同名，已被其覆盖", sk.Name, sk.ShadowedBy)})
+		}
+	}
+	return out
+}
@@ -1,20 +1,31 @@
 package skill
 
 import (
-	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/afumu/openlink/internal/types"
 )
 
 type Info struct {
-	Name        string
-	Description string
-	Dir         string
-	Location    string // absolute path to SKILL.md
+	Name         string
+	Description  string
+	AllowedTools []string // 非空时，skill 激活期间只允许调用这些工具
+	Version      string
+	Tags         []string
+	Keywords     []string // 触发关键词，用于相关度匹配
+	WhenToUse    string
+	Arguments    []Argument
+	ArgumentHint string // 未声明 arguments 时的自由格式参数提示
+	Dir          string
+	Location     string // absolute path to SKILL.md
+	Source       string // 所在的 skill 目录
+	ShadowedBy   string // 被优先级更高的同名 skill 覆盖时，为其 SKILL.md 路径
+	Err          error  // frontmatter 解析错误
 }
 
+// SkillDirs 返回默认的 skill 目录，按优先级从高到低排列。
 func SkillDirs(rootDir string) []string {
 	home, _ := os.UserHomeDir()
 	return []string{
@@ -28,90 +39,32 @@ func SkillDirs(rootDir string) []string {
 	}
 }
 
-func LoadInfos(rootDir string) []Info {
-	seen := map[string]Info{}
-	var order []string
-
-	for _, dir := range SkillDirs(rootDir) {
-		if _, err := os.Stat(dir); err != nil {
-			continue
-		}
-		log.Printf("[Skill] 扫描目录: %s", dir)
-		entries, err := os.ReadDir(dir)
-		if err != nil {
-			continue
-		}
-		for _, entry := range entries {
-			// 跟随软链接：用 os.Stat 而非 entry.Type()
-			subPath := filepath.Join(dir, entry.Name())
-			info, err := os.Stat(subPath)
-			if err != nil || !info.IsDir() {
-				continue
-			}
-			skillFile := findSkillMd(subPath)
-			if skillFile == "" {
-				continue
-			}
-			data, err := os.ReadFile(skillFile)
-			if err != nil {
-				continue
-			}
-			sk := parse(skillFile, string(data))
-			sk.Dir = subPath
-			sk.Location = skillFile
-			log.Printf("[Skill] 加载: name=%s description=%.60s", sk.Name, sk.Description)
-			if _, exists := seen[sk.Name]; !exists {
-				order = append(order, sk.Name)
-			}
-			seen[sk.Name] = sk
-		}
+// Dirs 返回工作目录的 skill 目录，按优先级从高到低排列：项目配置 pc.SkillDirs 非空时使用该顺序
+// （相对路径基于工作目录，"~/" 表示用户主目录），否则使用 SkillDirs。
+// pc 由调用方在启动时读取，运行期间修改 .openlink/config.json 不会改变扫描目录。
+func Dirs(rootDir string, pc types.ProjectConfig) []string {
+	if len(pc.SkillDirs) == 0 {
+		return SkillDirs(rootDir)
 	}
-
-	log.Printf("[Skill] 共加载 %d 个 skill", len(order))
-	result := make([]Info, 0, len(order))
-	for _, name := range order {
-		result = append(result, seen[name])
-	}
-	return result
-}
-
-func Get(rootDir, name string) (Info, bool) {
-	if strings.ContainsAny(name, "/\\") || strings.Contains(name, "..") {
-		return Info{}, false
-	}
-	for _, info := range LoadInfos(rootDir) {
-		if strings.EqualFold(info.Name, name) {
-			return info, true
+	home, _ := os.UserHomeDir()
+	dirs := make([]string, 0, len(pc.SkillDirs))
+	for _, d := range pc.SkillDirs {
+		switch {
+		case d == "~":
+			d = home
+		case strings.HasPrefix(d, "~/"):
+			d = filepath.Join(home, d[2:])
+		case !filepath.IsAbs(d):
+			d = filepath.Join(rootDir, d)
 		}
+		dirs = append(dirs, filepath.Clean(d))
 	}
-	return Info{}, false
+	return dirs
 }
 
-func FindSkill(rootDir, name string) (content, dir string, err error) {
-	if strings.ContainsAny(name, "/\\") || strings.Contains(name, "..") {
-		return "", "", fmt.Errorf("invalid skill name: %q", name)
-	}
-	for _, d := range SkillDirs(rootDir) {
-		// flat file: dir/<name>.md
-		p := filepath.Join(d, name+".md")
-		if data, e := os.ReadFile(p); e == nil {
-			return string(data), d, nil
-		}
-		// subdir: dir/<name>/SKILL.md (case-insensitive match on dir name)
-		entries, e := os.ReadDir(d)
-		if e != nil {
-			continue
-		}
-		for _, entry := range entries {
-			if entry.IsDir() && strings.EqualFold(entry.Name(), name) {
-				skillPath := filepath.Join(d, entry.Name(), "SKILL.md")
-				if data, e := os.ReadFile(skillPath); e == nil {
-					return string(data), filepath.Join(d, entry.Name()), nil
-				}
-			}
-		}
-	}
-	return "", "", fmt.Errorf("skill %q not found", name)
+// For 返回 config 对应的共享 skill 索引，扫描目录取自启动时加载的 config.Project。
+func For(config *types.Config) *Index {
+	return IndexFor(Dirs(config.RootDir, config.Project))
 }
 
 // findSkillMd 在目录下查找 SKILL.md（大小写不敏感）
@@ -128,29 +81,35 @@ func findSkillMd(dir string) string {
 	return ""
 }
 
-func parse(path, content string) Info {
-	name := filepath.Base(filepath.Dir(path))
-	description := ""
-
-	if !strings.HasPrefix(content, "---") {
-		return Info{Name: name, Description: description}
+// parse 从 SKILL.md 的 YAML frontmatter 读取元数据，name 缺省为所在目录名。
+// frontmatter 无法解析时仍返回以目录名命名的 Info，同时返回错误。
+func parse(path, content string) (Info, error) {
+	info := Info{Name: filepath.Base(filepath.Dir(path))}
+	fm, err := parseFrontmatter(content)
+	if err != nil {
+		return info, err
 	}
-	end := strings.Index(content[3:], "---")
-	if end < 0 {
-		return Info{Name: name, Description: description}
+	if name := strings.TrimSpace(fm.Name); name != "" {
+		info.Name = name
 	}
-	front := content[3 : end+3]
-	for _, line := range strings.Split(front, "\n") {
-		line = strings.TrimSpace(line)
-		if k, v, ok := strings.Cut(line, ":"); ok {
-			v = strings.TrimSpace(v)
-			switch strings.TrimSpace(k) {
-			case "name":
-				name = v
-			case "description":
-				description = v
-			}
-		}
+	info.Description = strings.TrimSpace(fm.Description)
+	info.AllowedTools = fm.AllowedTools
+	info.Version = strings.TrimSpace(string(fm.Version))
+	info.Tags = fm.Tags
+	info.Keywords = fm.Keywords
+	info.WhenToUse = strings.TrimSpace(fm.WhenToUse)
+	info.Arguments = fm.Arguments
+	info.ArgumentHint = strings.TrimSpace(fm.ArgumentHint)
+	return info, nil
+}
+
+// Parse 解析 Markdown 文件的 frontmatter，返回元数据和去掉 frontmatter 的正文，
+// 供斜杠命令等沿用 SKILL.md 格式的文件复用。
+func Parse(path, content string) (Info, string, error) {
+	info, err := parse(path, content)
+	_, body, ok := splitFrontmatter(content)
+	if !ok {
+		body = strings.TrimPrefix(content, "\ufeff")
 	}
-	return Info{Name: name, Description: description}
+	return info, body, err
 }
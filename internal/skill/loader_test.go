@@ -3,7 +3,10 @@ package skill
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/afumu/openlink/internal/types"
 )
 
 func TestGet(t *testing.T) {
@@ -15,34 +18,34 @@ func TestGet(t *testing.T) {
 		sub := filepath.Join(skillDir, "mysub")
 		os.MkdirAll(sub, 0755)
 		os.WriteFile(filepath.Join(sub, "SKILL.md"), []byte("---\nname: mysub\ndescription: test\n---\n"), 0644)
-		info, ok := Get(root, "mysub")
+		info, ok := For(&types.Config{RootDir: root}).Get("mysub")
 		if !ok || info.Name != "mysub" || info.Location == "" {
 			t.Errorf("got ok=%v info=%+v", ok, info)
 		}
 	})
 
 	t.Run("path traversal blocked", func(t *testing.T) {
-		_, ok := Get(root, "../../etc/passwd")
+		_, ok := For(&types.Config{RootDir: root}).Get("../../etc/passwd")
 		if ok {
 			t.Error("expected not found for path traversal")
 		}
 	})
 
 	t.Run("unknown skill returns false", func(t *testing.T) {
-		_, ok := Get(root, "nonexistent")
+		_, ok := For(&types.Config{RootDir: root}).Get("nonexistent")
 		if ok {
 			t.Error("expected not found")
 		}
 	})
 }
 
-func TestLoadInfos(t *testing.T) {
+func TestForInfos(t *testing.T) {
 	root := t.TempDir()
 	skillDir := filepath.Join(root, ".skills", "myskill")
 	os.MkdirAll(skillDir, 0755)
 	os.WriteFile(filepath.Join(skillDir, "skill.md"), []byte("---\nname: myskill\ndescription: does stuff\n---\n"), 0644)
 
-	infos := LoadInfos(root)
+	infos := For(&types.Config{RootDir: root}).Infos()
 	if len(infos) == 0 {
 		t.Fatal("expected at least one skill")
 	}
@@ -56,3 +59,61 @@ func TestLoadInfos(t *testing.T) {
 		t.Errorf("skill not found in %+v", infos)
 	}
 }
+
+func TestParseFrontmatter(t *testing.T) {
+	content := `---
+name: deploy
+description: |
+  Deploy the service.
+  Use after tests pass.
+version: 1.20
+allowed-tools: exec_cmd, read_file
+tags: [ops, release]
+when-to-use: "when the user says: ship it"
+arguments:
+  - name: env
+    description: target environment
+    required: true
+  - name: tag
+    default: latest
+---
+body with --- inside
+`
+	info, err := parse("/x/deploy-dir/SKILL.md", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "deploy" || info.Description != "Deploy the service.\nUse after tests pass." || info.Version != "1.20" {
+		t.Errorf("got %+v", info)
+	}
+	if strings.Join(info.AllowedTools, ",") != "exec_cmd,read_file" || strings.Join(info.Tags, ",") != "ops,release" {
+		t.Errorf("lists: %+v", info)
+	}
+	if info.WhenToUse != "when the user says: ship it" {
+		t.Errorf("when-to-use = %q", info.WhenToUse)
+	}
+	if len(info.Arguments) != 2 || !info.Arguments[0].Required || info.Arguments[1].Default != "latest" {
+		t.Errorf("arguments = %+v", info.Arguments)
+	}
+
+	t.Run("argument names and list form", func(t *testing.T) {
+		info, err := parse("/x/a/SKILL.md", "---\nversion: \"2.0\"\nallowed-tools:\n  - grep\narguments: [file]\n---\n")
+		if err != nil || info.Name != "a" || info.Version != "2.0" || info.AllowedTools[0] != "grep" || info.Arguments[0].Name != "file" {
+			t.Errorf("got %+v, %v", info, err)
+		}
+	})
+
+	t.Run("invalid yaml falls back to directory name", func(t *testing.T) {
+		info, err := parse("/x/broken/SKILL.md", "---\nname: [unclosed\n---\n")
+		if err == nil || info.Name != "broken" {
+			t.Errorf("got %+v, %v", info, err)
+		}
+	})
+
+	t.Run("no frontmatter", func(t *testing.T) {
+		info, err := parse("/x/plain/SKILL.md", "# just markdown\n---\n")
+		if err != nil || info.Name != "plain" || info.Description != "" {
+			t.Errorf("got %+v, %v", info, err)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package skill
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 各字段在相关度计算中的权重：名称和关键词最能说明 skill 的用途。
+const (
+	weightName        = 3
+	weightKeywords    = 3
+	weightTags        = 2
+	weightDescription = 1
+	weightWhenToUse   = 1
+)
+
+// BM25 参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Match 是一条相关度匹配结果。
+type Match struct {
+	Info  Info
+	Score float64
+}
+
+// Search 按与 query 的相关度返回索引中的 skill，最多 limit 条（limit <= 0 时不限）。
+func (ix *Index) Search(query string, limit int) []Match {
+	return Rank(ix.Infos(), query, limit)
+}
+
+// Rank 用 BM25 对 skill 的名称、关键词、标签、描述和 when-to-use 打分，
+// 只返回得分大于 0 的结果，按得分从高到低排列，同分按名称排序。
+func Rank(infos []Info, query string, limit int) []Match {
+	terms := uniqueTerms(tokenize(query))
+	if len(terms) == 0 || len(infos) == 0 {
+		return nil
+	}
+
+	docs := make([]map[string]float64, len(infos))
+	lengths := make([]float64, len(infos))
+	df := make(map[string]int)
+	var total float64
+	for i, info := range infos {
+		docs[i] = termWeights(info)
+		for t, w := range docs[i] {
+			lengths[i] += w
+			df[t]++
+		}
+		total += lengths[i]
+	}
+	avg := total / float64(len(infos))
+	if avg == 0 {
+		return nil
+	}
+
+	n := float64(len(infos))
+	var matches []Match
+	for i, info := range infos {
+		var score float64
+		for _, t := range terms {
+			tf := docs[i][t]
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + (n-float64(df[t])+0.5)/(float64(df[t])+0.5))
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*lengths[i]/avg))
+		}
+		if score > 0 {
+			matches = append(matches, Match{Info: info, Score: score})
+		}
+	}
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return strings.ToLower(matches[a].Info.Name) < strings.ToLower(matches[b].Info.Name)
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// termWeights 汇总 skill 各字段的词频，按字段权重累加。
+func termWeights(info Info) map[string]float64 {
+	w := make(map[string]float64)
+	add := func(text string, weight float64) {
+		for _, t := range tokenize(text) {
+			w[t] += weight
+		}
+	}
+	add(info.Name, weightName)
+	add(strings.Join(info.Keywords, " "), weightKeywords)
+	add(strings.Join(info.Tags, " "), weightTags)
+	add(info.Description, weightDescription)
+	add(info.WhenToUse, weightWhenToUse)
+	return w
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "can": true, "do": true, "for": true, "from": true, "how": true, "i": true,
+	"in": true, "is": true, "it": true, "me": true, "my": true, "of": true, "on": true,
+	"or": true, "please": true, "the": true, "this": true, "that": true, "to": true,
+	"use": true, "when": true, "with": true, "you": true,
+}
+
+// tokenize 把文本切成小写词项：英文和数字按单词切分并去掉复数 s，
+// 连续的汉字切成二元组（单个汉字保留原样），不依赖分词词典。
+func tokenize(text string) []string {
+	var out []string
+	var word []rune
+	var han []rune
+	flushWord := func() {
+		if len(word) == 0 {
+			return
+		}
+		w := string(word)
+		word = word[:0]
+		if stopWords[w] {
+			return
+		}
+		if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+			w = w[:len(w)-1]
+		}
+		out = append(out, w)
+	}
+	flushHan := func() {
+		switch len(han) {
+		case 0:
+		case 1:
+			out = append(out, string(han))
+		default:
+			for i := 0; i+1 < len(han); i++ {
+				out = append(out, string(han[i:i+2]))
+			}
+		}
+		han = han[:0]
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+	return out
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	out := terms[:0]
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
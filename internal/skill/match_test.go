@@ -0,0 +1,45 @@
+package skill
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("Deploy the PDFs to 生产环境, please!")
+	want := []string{"deploy", "pdf", "生产", "产环", "环境"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRank(t *testing.T) {
+	infos := []Info{
+		{Name: "pdf", Description: "Fill and merge PDF forms", Keywords: []string{"acroform"}},
+		{Name: "deploy", Description: "Deploy the service to staging or production", Tags: []string{"ops"}},
+		{Name: "wechat-article-writer", Description: "撰写微信公众号文章", Keywords: []string{"公众号", "推文"}},
+		{Name: "changelog", Description: "Write release notes from git history", WhenToUse: "before tagging a release"},
+	}
+	cases := []struct {
+		query, want string
+	}{
+		{"please deploy this to production", "deploy"},
+		{"merge these two pdf files", "pdf"},
+		{"帮我写一篇公众号推文", "wechat-article-writer"},
+		{"draft the release notes", "changelog"},
+		{"acroforms", "pdf"},
+	}
+	for _, c := range cases {
+		got := Rank(infos, c.query, 0)
+		if len(got) == 0 || got[0].Info.Name != c.want {
+			t.Errorf("Rank(%q) = %+v, want %s first", c.query, got, c.want)
+		}
+	}
+
+	if got := Rank(infos, "kubernetes helm chart", 0); len(got) != 0 {
+		t.Errorf("expected no matches, got %+v", got)
+	}
+	if got := Rank(infos, "write deploy pdf release", 2); len(got) != 2 {
+		t.Errorf("limit not applied: %+v", got)
+	}
+}
@@ -0,0 +1,74 @@
+package skill
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/afumu/openlink/internal/security"
+)
+
+// MaxResources 是资源清单列出的文件数上限。
+const MaxResources = 200
+
+// Resource 是 skill 目录下的一个资源文件。
+type Resource struct {
+	Path string `json:"path"` // 相对 skill 目录、以 / 分隔的路径
+	Size int64  `json:"size"`
+}
+
+// Resources 递归列出 skill 目录下除 SKILL.md 外的普通文件，跳过以 . 开头的文件和目录；
+// 超过 MaxResources 个时截断并返回 truncated=true。
+func Resources(info Info) (list []Resource, truncated bool, err error) {
+	err = filepath.WalkDir(info.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == info.Dir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || path == info.Location {
+			return nil
+		}
+		if len(list) == MaxResources {
+			truncated = true
+			return filepath.SkipAll
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(info.Dir, path)
+		list = append(list, Resource{Path: filepath.ToSlash(rel), Size: fi.Size()})
+		return nil
+	})
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	return list, truncated, err
+}
+
+// ResourcePath 将相对路径解析为 skill 目录内的绝对路径，越出 skill 目录时返回错误。
+func ResourcePath(info Info, rel string) (string, error) {
+	if filepath.IsAbs(rel) {
+		return "", fmt.Errorf("resource path must be relative to the skill directory: %s", rel)
+	}
+	return security.SafePath(info.Dir, filepath.FromSlash(rel))
+}
+
+// FormatSize 以 B/KB/MB 形式显示文件大小。
+func FormatSize(n int64) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d B", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+}
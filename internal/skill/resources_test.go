@@ -0,0 +1,62 @@
+package skill
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResources(t *testing.T) {
+	dir := t.TempDir()
+	writeSkill(t, dir, "docs", "d")
+	root := filepath.Join(dir, "docs")
+	os.MkdirAll(filepath.Join(root, "references", "deep"), 0755)
+	os.MkdirAll(filepath.Join(root, ".git"), 0755)
+	os.WriteFile(filepath.Join(root, "references", "api.md"), []byte("api"), 0644)
+	os.WriteFile(filepath.Join(root, "references", "deep", "x.txt"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(root, "run.sh"), []byte("echo"), 0755)
+	os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("ref"), 0644)
+
+	info, ok := NewIndex([]string{dir}).Get("docs")
+	if !ok {
+		t.Fatal("skill not found")
+	}
+	list, truncated, err := Resources(info)
+	if err != nil || truncated {
+		t.Fatal(err, truncated)
+	}
+	want := []string{"references/api.md", "references/deep/x.txt", "run.sh"}
+	if len(list) != len(want) {
+		t.Fatalf("got %+v", list)
+	}
+	for i, r := range list {
+		if r.Path != want[i] {
+			t.Errorf("list[%d] = %+v, want %s", i, r, want[i])
+		}
+	}
+	if list[0].Size != 3 {
+		t.Errorf("size = %d", list[0].Size)
+	}
+
+	if _, err := ResourcePath(info, "references/api.md"); err != nil {
+		t.Error(err)
+	}
+	for _, bad := range []string{"../../etc/passwd", "/etc/passwd"} {
+		if _, err := ResourcePath(info, bad); err == nil {
+			t.Errorf("%s: expected error", bad)
+		}
+	}
+}
+
+func TestResourcesCap(t *testing.T) {
+	dir := t.TempDir()
+	writeSkill(t, dir, "big", "d")
+	for i := 0; i < MaxResources+5; i++ {
+		os.WriteFile(filepath.Join(dir, "big", "f"+string(rune('a'+i%26))+string(rune('a'+i/26))), nil, 0644)
+	}
+	info, _ := NewIndex([]string{dir}).Get("big")
+	list, truncated, err := Resources(info)
+	if err != nil || !truncated || len(list) != MaxResources {
+		t.Errorf("len=%d truncated=%v err=%v", len(list), truncated, err)
+	}
+}
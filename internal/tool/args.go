@@ -0,0 +1,109 @@
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 工具参数既可能来自 JSON 调用（数字为 float64、布尔为 bool），
+// 也可能来自 XML parameter 格式（所有值都是字符串），以下辅助函数同时兼容两种形式。
+
+// intArg 读取整数参数。
+func intArg(args map[string]interface{}, key string) (int, bool) {
+	switch v := args[key].(type) {
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	case int:
+		return v, true
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return 0, false
+		}
+		return n, true
+	}
+	return 0, false
+}
+
+// boolArg 读取布尔参数，缺省为 false。
+func boolArg(args map[string]interface{}, key string) bool {
+	switch v := args[key].(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(strings.TrimSpace(v))
+		return b
+	}
+	return false
+}
+
+// stringListArg 读取单个字符串、字符串数组或 JSON 数组字符串形式的参数。
+func stringListArg(args map[string]interface{}, key string) []string {
+	switch v := args[key].(type) {
+	case string:
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, "[") {
+			var list []string
+			if err := json.Unmarshal([]byte(v), &list); err == nil {
+				return list
+			}
+		}
+		if v != "" {
+			return []string{v}
+		}
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case []string:
+		return v
+	}
+	return nil
+}
+
+// stringMapArg 读取对象、JSON 对象字符串或每行一个 "Key: Value" 形式的参数。
+func stringMapArg(args map[string]interface{}, key string) (map[string]string, error) {
+	out := map[string]string{}
+	switch v := args[key].(type) {
+	case nil:
+	case map[string]interface{}:
+		for k, val := range v {
+			out[k] = fmt.Sprint(val)
+		}
+	case map[string]string:
+		for k, val := range v {
+			out[k] = val
+		}
+	case string:
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, "{") {
+			var m map[string]interface{}
+			if err := json.Unmarshal([]byte(v), &m); err != nil {
+				return nil, fmt.Errorf("%s: invalid JSON object: %w", key, err)
+			}
+			return stringMapArg(map[string]interface{}{key: m}, key)
+		}
+		for _, line := range strings.Split(v, "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			k, val, ok := strings.Cut(line, ":")
+			if !ok {
+				return nil, fmt.Errorf("%s: expected \"Key: Value\", got %q", key, line)
+			}
+			out[strings.TrimSpace(k)] = strings.TrimSpace(val)
+		}
+	default:
+		return nil, fmt.Errorf("%s must be an object", key)
+	}
+	return out, nil
+}
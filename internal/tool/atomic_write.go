@@ -0,0 +1,103 @@
+package tool
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// defaultFileMode 是新建文件的默认权限。
+const defaultFileMode os.FileMode = 0644
+
+// writeFileAtomic 是所有文件修改工具共用的写入路径：先写入同目录下的临时文件并 fsync，
+// 再按原文件恢复权限与属主，最后 rename 覆盖目标，避免中途崩溃导致文件被截断。
+// perm 仅在目标文件不存在时生效，与 os.WriteFile 一样受进程 umask 约束。
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return writeAtomic(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeAtomic 与 writeFileAtomic 相同，但由 fill 流式写入内容；fill 返回错误时放弃写入，
+// 目标文件保持不变。
+func writeAtomic(path string, perm os.FileMode, fill func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", path)
+		}
+		perm = info.Mode().Perm()
+	case os.IsNotExist(err):
+		info = nil
+	default:
+		return err
+	}
+
+	tmp, err := createTemp(dir, "."+filepath.Base(path)+".tmp-", perm)
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		if tmpName != "" {
+			os.Remove(tmpName)
+		}
+	}()
+
+	if err := fill(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if info != nil {
+		// 临时文件创建时受 umask 影响，覆盖已有文件时恢复原权限
+		if err := os.Chmod(tmpName, perm); err != nil {
+			return err
+		}
+		preserveOwner(tmpName, info)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	tmpName = ""
+	syncDir(dir)
+	return nil
+}
+
+// createTemp 与 os.CreateTemp 相同，但以 perm 创建文件，由系统按 umask 裁剪权限。
+func createTemp(dir, prefix string, perm os.FileMode) (*os.File, error) {
+	for i := 0; i < 10000; i++ {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(uint64(rand.Uint32()), 10))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if os.IsExist(err) {
+			continue
+		}
+		return f, err
+	}
+	return nil, fmt.Errorf("cannot create temporary file in %s", dir)
+}
+
+// parseFileMode 解析八进制权限字符串（如 "0755"、"600"）。
+func parseFileMode(s string) (os.FileMode, error) {
+	v, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || v > 0777 {
+		return 0, fmt.Errorf("invalid file mode %q (expected octal such as 0644)", s)
+	}
+	return os.FileMode(v), nil
+}
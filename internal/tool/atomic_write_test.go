@@ -0,0 +1,90 @@
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permission bits")
+	}
+	cfg := testConfig(t)
+
+	t.Run("preserves existing mode", func(t *testing.T) {
+		p := filepath.Join(cfg.RootDir, "run.sh")
+		os.WriteFile(p, []byte("#!/bin/sh\n"), 0755)
+		os.Chmod(p, 0750)
+		if err := writeFileAtomic(p, []byte("#!/bin/sh\necho hi\n"), defaultFileMode); err != nil {
+			t.Fatal(err)
+		}
+		info, _ := os.Stat(p)
+		if info.Mode().Perm() != 0750 {
+			t.Errorf("expected 0750, got %o", info.Mode().Perm())
+		}
+	})
+
+	t.Run("edit keeps private file private", func(t *testing.T) {
+		p := filepath.Join(cfg.RootDir, "secret.txt")
+		os.WriteFile(p, []byte("token=a\n"), 0600)
+		res := NewEditTool(cfg).Execute(testCtx(cfg, map[string]interface{}{"path": "secret.txt", "old_string": "token=a", "new_string": "token=b"}))
+		if res.Status != "success" {
+			t.Fatalf("edit failed: %s", res.Error)
+		}
+		info, _ := os.Stat(p)
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("expected 0600, got %o", info.Mode().Perm())
+		}
+	})
+
+	t.Run("new file honours perm argument", func(t *testing.T) {
+		res := NewWriteFileTool(cfg).Execute(testCtx(cfg, map[string]interface{}{"path": "bin/tool", "content": "x", "perm": "0755"}))
+		if res.Status != "success" {
+			t.Fatalf("write failed: %s", res.Error)
+		}
+		info, _ := os.Stat(filepath.Join(cfg.RootDir, "bin/tool"))
+		if info.Mode().Perm() != 0755 {
+			t.Errorf("expected 0755, got %o", info.Mode().Perm())
+		}
+	})
+
+	t.Run("append with perm on a new file", func(t *testing.T) {
+		w := NewWriteFileTool(cfg)
+		w.Execute(testCtx(cfg, map[string]interface{}{"path": "bin/run.sh", "content": "#!/bin/sh\n", "mode": "append", "perm": "0750"}))
+		res := w.Execute(testCtx(cfg, map[string]interface{}{"path": "bin/run.sh", "content": "echo hi\n", "mode": "append", "perm": "0600"}))
+		if res.Status != "success" {
+			t.Fatalf("write failed: %s", res.Error)
+		}
+		data, _ := os.ReadFile(filepath.Join(cfg.RootDir, "bin/run.sh"))
+		info, _ := os.Stat(filepath.Join(cfg.RootDir, "bin/run.sh"))
+		if string(data) != "#!/bin/sh\necho hi\n" || info.Mode().Perm() != 0750 {
+			t.Errorf("got %q %o", data, info.Mode().Perm())
+		}
+	})
+
+	t.Run("invalid mode and perm rejected", func(t *testing.T) {
+		w := NewWriteFileTool(cfg)
+		for _, args := range []map[string]interface{}{
+			{"path": "a", "mode": "appendx"},
+			{"path": "a", "mode": "0755"},
+			{"path": "a", "perm": "rwx"},
+		} {
+			if err := w.Validate(args); err == nil {
+				t.Errorf("%v: expected error", args)
+			}
+		}
+	})
+
+	t.Run("leaves no temp files behind", func(t *testing.T) {
+		dir := filepath.Join(cfg.RootDir, "clean")
+		if err := writeFileAtomic(filepath.Join(dir, "a.txt"), []byte("a"), defaultFileMode); err != nil {
+			t.Fatal(err)
+		}
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 1 {
+			t.Errorf("expected only a.txt, got %d entries", len(entries))
+		}
+	})
+}
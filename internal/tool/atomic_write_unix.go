@@ -0,0 +1,30 @@
+//go:build !windows
+
+package tool
+
+import (
+	"os"
+	"syscall"
+)
+
+// preserveOwner 尽力把临时文件的属主改回原文件的属主；无权限时静默忽略。
+func preserveOwner(path string, orig os.FileInfo) {
+	st, ok := orig.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	if st.Uid == uint32(os.Getuid()) && st.Gid == uint32(os.Getgid()) {
+		return
+	}
+	os.Chown(path, int(st.Uid), int(st.Gid))
+}
+
+// syncDir 刷新目录项，确保 rename 在崩溃后依然可见。
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
@@ -0,0 +1,33 @@
+//go:build !windows
+
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestWriteFileAtomicUmask(t *testing.T) {
+	old := syscall.Umask(077)
+	defer syscall.Umask(old)
+
+	dir := t.TempDir()
+	p := filepath.Join(dir, "secret.txt")
+	if err := writeFileAtomic(p, []byte("x"), defaultFileMode); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(p); info.Mode().Perm() != 0600 {
+		t.Errorf("new file should honour umask 077, got %o", info.Mode().Perm())
+	}
+
+	// 覆盖已有文件时保留原权限，不受 umask 影响
+	os.Chmod(p, 0644)
+	if err := writeFileAtomic(p, []byte("y"), defaultFileMode); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(p); info.Mode().Perm() != 0644 {
+		t.Errorf("existing mode should be preserved, got %o", info.Mode().Perm())
+	}
+}
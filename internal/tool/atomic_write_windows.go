@@ -0,0 +1,10 @@
+//go:build windows
+
+package tool
+
+import "os"
+
+// Windows 上文件属主由 ACL 继承，无需额外处理。
+func preserveOwner(path string, orig os.FileInfo) {}
+
+func syncDir(dir string) {}
@@ -0,0 +1,155 @@
+package tool
+
+import (
+	"path"
+	"strings"
+)
+
+// globMatcher 实现 doublestar 语义：`**` 匹配任意层级目录，`*`/`?`/`[...]` 只在单个路径段内匹配，
+// 支持 `{a,b}` 花括号展开。不含 `/` 的模式按文件名在任意层级匹配（等价于 `**/pattern`）。
+// hidden 为 false 时通配符不匹配以 `.` 开头的文件或目录，只有字面写出的 `.xxx` 段才能命中。
+type globMatcher struct {
+	patterns [][]string
+	hidden   bool
+}
+
+func newGlobMatcher(patterns []string, hidden bool) *globMatcher {
+	m := &globMatcher{hidden: hidden}
+	for _, p := range patterns {
+		for _, e := range expandBraces(p) {
+			e = strings.TrimPrefix(path.Clean(strings.ReplaceAll(e, "\\", "/")), "./")
+			if !strings.Contains(e, "/") {
+				e = "**/" + e
+			}
+			m.patterns = append(m.patterns, strings.Split(e, "/"))
+		}
+	}
+	return m
+}
+
+// NamesSegment 判断是否有模式把 name 作为字面路径段写出，如 dist/**/*.js 中的 dist。
+func (m *globMatcher) NamesSegment(name string) bool {
+	for _, p := range m.patterns {
+		for _, seg := range p {
+			if seg == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Match 判断相对路径 rel（以 / 分隔）是否匹配任一模式。
+func (m *globMatcher) Match(rel string) bool {
+	segs := strings.Split(rel, "/")
+	for _, p := range m.patterns {
+		if matchSegments(p, segs, false, m.hidden) {
+			return true
+		}
+	}
+	return false
+}
+
+// CouldMatchUnder 判断目录 rel 之下是否可能存在匹配项，用于遍历时剪枝。
+func (m *globMatcher) CouldMatchUnder(rel string) bool {
+	segs := strings.Split(rel, "/")
+	for _, p := range m.patterns {
+		if matchSegments(p, segs, true, m.hidden) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments 逐段匹配；prefix 为 true 时，只要 segs 能作为某个匹配路径的前缀即返回 true。
+func matchSegments(pat, segs []string, prefix, hidden bool) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			rest := pat[1:]
+			for len(rest) > 0 && rest[0] == "**" {
+				rest = rest[1:]
+			}
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(rest, segs[i:], prefix, hidden) {
+					return true
+				}
+				if i < len(segs) && !hidden && isHiddenName(segs[i]) {
+					return false
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return prefix
+		}
+		if !matchSegment(pat[0], segs[0], hidden) {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+func matchSegment(pat, name string, hidden bool) bool {
+	if !hidden && isHiddenName(name) && !strings.HasPrefix(pat, ".") {
+		return false
+	}
+	ok, _ := path.Match(pat, name)
+	return ok
+}
+
+func isHiddenName(name string) bool {
+	return len(name) > 1 && name[0] == '.' && name != ".."
+}
+
+// expandBraces 展开 `{a,b}` 形式（支持嵌套），如 `*.{ts,tsx}` → `*.ts`, `*.tsx`。
+func expandBraces(p string) []string {
+	depth, start := 0, -1
+	for i := 0; i < len(p); i++ {
+		switch p[i] {
+		case '\\':
+			i++
+		case '{':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth != 0 {
+				continue
+			}
+			var out []string
+			for _, alt := range splitTopLevel(p[start+1 : i]) {
+				out = append(out, expandBraces(p[:start]+alt+p[i+1:])...)
+			}
+			return out
+		}
+	}
+	return []string{p}
+}
+
+// splitTopLevel 按不在嵌套花括号内的逗号切分。
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, last := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, s[last:])
+}
@@ -2,7 +2,6 @@ package tool
 
 import (
 	"errors"
-	"fmt"
 	"math"
 	"os"
 	"path/filepath"
@@ -22,14 +21,25 @@ func NewEditTool(config *types.Config) *EditTool {
 	return &EditTool{config: config}
 }
 
-func (t *EditTool) Name() string        { return "edit" }
-func (t *EditTool) Description() string { return "Replace a string in a file (exact match)" }
+func (t *EditTool) Name() string { return "edit" }
+func (t *EditTool) Description() string {
+	return "Edit a file: exact string replace (default), line range replace, insert at line/anchor, or regex replace"
+}
 func (t *EditTool) Parameters() interface{} {
 	return map[string]string{
-		"path":        "string (required) - file path",
-		"old_string":  "string (required) - text to replace",
-		"new_string":  "string (required) - replacement text",
-		"replace_all": "bool (optional) - replace all occurrences (default false)",
+		"path":           "string (required) - file path",
+		"mode":           "string (optional) - 'replace' (default), 'lines', 'insert' or 'regex'",
+		"old_string":     "string (required for replace) - text to replace",
+		"new_string":     "string (required) - replacement or inserted text; for regex supports $1/${name} capture groups",
+		"replace_all":    "bool (optional, replace) - replace all occurrences (default false)",
+		"start_line":     "number (required for lines) - first line to replace, 1-based",
+		"end_line":       "number (optional, lines) - last line to replace, inclusive (default: start_line)",
+		"line":           "number (insert) - insert relative to this line, 1-based; 0 with position=after inserts at top",
+		"anchor":         "string (insert) - regex matching exactly one line to insert relative to (instead of line)",
+		"position":       "string (optional, insert) - 'before' or 'after' (default)",
+		"pattern":        "string (required for regex) - Go regular expression",
+		"expected_count": "number (optional, regex) - fail unless the pattern matches exactly this many times",
+		"expected_hash":  "string (optional) - refuse to edit unless the current file hash (from read_file) matches",
 	}
 }
 
@@ -37,21 +47,20 @@ func (t *EditTool) Validate(args map[string]interface{}) error {
 	if p, ok := args["path"].(string); !ok || p == "" {
 		return errors.New("path is required")
 	}
-	if _, ok := args["old_string"].(string); !ok {
-		return errors.New("old_string is required")
-	}
-	if _, ok := args["new_string"].(string); !ok {
-		return errors.New("new_string is required")
-	}
-	return nil
+	mode, _ := args["mode"].(string)
+	return validateEditMode(mode, args)
 }
 
 func (t *EditTool) Execute(ctx *Context) *Result {
 	result := &Result{StartTime: time.Now()}
 	path, _ := ctx.Args["path"].(string)
-	oldStr, _ := ctx.Args["old_string"].(string)
-	newStr, _ := ctx.Args["new_string"].(string)
-	replaceAll, _ := ctx.Args["replace_all"].(bool)
+	mode, _ := ctx.Args["mode"].(string)
+
+	if err := validateEditMode(mode, ctx.Args); err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
 
 	var safePath string
 	var err error
@@ -66,6 +75,17 @@ func (t *EditTool) Execute(ctx *Context) *Result {
 		return result
 	}
 
+	if err := checkWritable(ctx.Config.RootDir, safePath); err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	if err := checkFresh(ctx, safePath); err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+
 	rawContent, err := os.ReadFile(safePath)
 	if err != nil {
 		result.Status = "error"
@@ -75,21 +95,22 @@ func (t *EditTool) Execute(ctx *Context) *Result {
 
 	content := normalizeLineEndings(string(rawContent))
 
-	replaced, err := replace(content, oldStr, newStr, replaceAll)
+	replaced, summary, err := applyEdit(mode, content, ctx.Args)
 	if err != nil {
 		result.Status = "error"
 		result.Error = err.Error()
 		return result
 	}
 
-	if err := os.WriteFile(safePath, []byte(replaced), 0644); err != nil {
+	if err := writeFileAtomic(safePath, []byte(replaced), defaultFileMode); err != nil {
 		result.Status = "error"
 		result.Error = err.Error()
 		return result
 	}
 
+	recordWrite(ctx, safePath)
 	result.Status = "success"
-	result.Output = fmt.Sprintf("已替换 '%s' → '%s'", oldStr, newStr)
+	result.Output = summary
 	result.EndTime = time.Now()
 	return result
 }
@@ -0,0 +1,222 @@
+package tool
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 编辑模式：除默认的精确替换外，支持按行区间替换、按行号/锚点插入以及正则替换。
+const (
+	editModeReplace = "replace"
+	editModeLines   = "lines"
+	editModeInsert  = "insert"
+	editModeRegex   = "regex"
+)
+
+// validateEditMode 按模式校验参数，Validate 与 Execute 共用。
+func validateEditMode(mode string, args map[string]interface{}) error {
+	switch mode {
+	case "", editModeReplace:
+		if _, ok := args["old_string"].(string); !ok {
+			return errors.New("old_string is required")
+		}
+		if _, ok := args["new_string"].(string); !ok {
+			return errors.New("new_string is required")
+		}
+	case editModeLines:
+		start, ok := intArg(args, "start_line")
+		if !ok || start < 1 {
+			return errors.New("start_line is required and must be >= 1")
+		}
+		if end, ok := intArg(args, "end_line"); ok && end < start {
+			return errors.New("end_line must be >= start_line")
+		}
+		if _, ok := args["new_string"].(string); !ok {
+			return errors.New("new_string is required")
+		}
+	case editModeInsert:
+		if _, ok := args["new_string"].(string); !ok {
+			return errors.New("new_string is required")
+		}
+		_, hasLine := intArg(args, "line")
+		anchor, _ := args["anchor"].(string)
+		if hasLine == (anchor != "") {
+			return errors.New("exactly one of line or anchor is required")
+		}
+		if anchor != "" {
+			if _, err := regexp.Compile(anchor); err != nil {
+				return fmt.Errorf("invalid anchor: %w", err)
+			}
+		}
+		if pos, _ := args["position"].(string); pos != "" && pos != "before" && pos != "after" {
+			return errors.New("position must be 'before' or 'after'")
+		}
+	case editModeRegex:
+		pattern, _ := args["pattern"].(string)
+		if pattern == "" {
+			return errors.New("pattern is required")
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		if _, ok := args["new_string"].(string); !ok {
+			return errors.New("new_string is required")
+		}
+		if n, ok := intArg(args, "expected_count"); ok && n < 1 {
+			return errors.New("expected_count must be >= 1")
+		}
+	default:
+		return fmt.Errorf("unknown mode %q (supported: replace, lines, insert, regex)", mode)
+	}
+	return nil
+}
+
+// applyEdit 根据模式对 content 做修改，返回新内容与结果摘要。
+func applyEdit(mode, content string, args map[string]interface{}) (string, string, error) {
+	newStr, _ := args["new_string"].(string)
+	switch mode {
+	case editModeLines:
+		start, _ := intArg(args, "start_line")
+		end, ok := intArg(args, "end_line")
+		if !ok {
+			end = start
+		}
+		out, err := replaceLineRange(content, start, end, newStr)
+		if err != nil {
+			return "", "", err
+		}
+		return out, fmt.Sprintf("已替换第 %d-%d 行", start, end), nil
+	case editModeInsert:
+		after := true
+		if pos, _ := args["position"].(string); pos == "before" {
+			after = false
+		}
+		line, hasLine := intArg(args, "line")
+		if !hasLine {
+			anchor, _ := args["anchor"].(string)
+			n, err := findAnchorLine(content, anchor)
+			if err != nil {
+				return "", "", err
+			}
+			line = n
+		}
+		out, err := insertAtLine(content, line, after, newStr)
+		if err != nil {
+			return "", "", err
+		}
+		where := "之后"
+		if !after {
+			where = "之前"
+		}
+		return out, fmt.Sprintf("已在第 %d 行%s插入", line, where), nil
+	case editModeRegex:
+		pattern, _ := args["pattern"].(string)
+		expected, hasExpected := intArg(args, "expected_count")
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid pattern: %w", err)
+		}
+		count := len(re.FindAllStringIndex(content, -1))
+		if count == 0 {
+			return "", "", fmt.Errorf("pattern %q matched nothing in the file", pattern)
+		}
+		if hasExpected && count != expected {
+			return "", "", fmt.Errorf("pattern %q matched %d times, expected %d", pattern, count, expected)
+		}
+		return re.ReplaceAllString(content, newStr), fmt.Sprintf("已替换 %d 处匹配", count), nil
+	default:
+		oldStr, _ := args["old_string"].(string)
+		replaceAll, _ := args["replace_all"].(bool)
+		out, err := replace(content, oldStr, newStr, replaceAll)
+		if err != nil {
+			return "", "", err
+		}
+		return out, fmt.Sprintf("已替换 '%s' → '%s'", oldStr, newStr), nil
+	}
+}
+
+// splitLines 将内容拆分为行，并记录末尾是否有换行符，便于原样拼回。
+func splitLines(content string) ([]string, bool) {
+	if content == "" {
+		return nil, false
+	}
+	trailing := strings.HasSuffix(content, "\n")
+	if trailing {
+		content = content[:len(content)-1]
+	}
+	return strings.Split(content, "\n"), trailing
+}
+
+func joinLines(lines []string, trailing bool) string {
+	out := strings.Join(lines, "\n")
+	if trailing {
+		out += "\n"
+	}
+	return out
+}
+
+// textLines 把待写入的文本拆成行；忽略末尾的一个换行，空串表示不插入任何行。
+func textLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// replaceLineRange 用 newStr 替换第 start 到 end 行（1-based，闭区间）；newStr 为空即删除这些行。
+func replaceLineRange(content string, start, end int, newStr string) (string, error) {
+	lines, trailing := splitLines(content)
+	if start > len(lines) || end > len(lines) {
+		return "", fmt.Errorf("line range %d-%d out of bounds (file has %d lines)", start, end, len(lines))
+	}
+	out := make([]string, 0, len(lines))
+	out = append(out, lines[:start-1]...)
+	out = append(out, textLines(newStr)...)
+	out = append(out, lines[end:]...)
+	return joinLines(out, trailing), nil
+}
+
+// insertAtLine 在第 line 行之前或之后插入 newStr。
+// after 时 line 可为 0（插入到文件开头）；before 时 line 可为总行数+1（追加到末尾）。
+func insertAtLine(content string, line int, after bool, newStr string) (string, error) {
+	lines, trailing := splitLines(content)
+	idx := line - 1
+	if after {
+		idx = line
+	}
+	if idx < 0 || idx > len(lines) {
+		return "", fmt.Errorf("line %d out of bounds (file has %d lines)", line, len(lines))
+	}
+	if len(lines) == 0 {
+		trailing = true
+	}
+	out := make([]string, 0, len(lines)+1)
+	out = append(out, lines[:idx]...)
+	out = append(out, textLines(newStr)...)
+	out = append(out, lines[idx:]...)
+	return joinLines(out, trailing), nil
+}
+
+// findAnchorLine 返回唯一匹配 anchor 的行号（1-based）。
+func findAnchorLine(content, anchor string) (int, error) {
+	re, err := regexp.Compile(anchor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid anchor: %w", err)
+	}
+	lines, _ := splitLines(content)
+	found := 0
+	for i, l := range lines {
+		if re.MatchString(l) {
+			if found != 0 {
+				return 0, fmt.Errorf("anchor %q matches multiple lines (%d and %d); make it more specific", anchor, found, i+1)
+			}
+			found = i + 1
+		}
+	}
+	if found == 0 {
+		return 0, fmt.Errorf("anchor %q matched no line", anchor)
+	}
+	return found, nil
+}
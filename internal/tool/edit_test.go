@@ -1,6 +1,8 @@
 package tool
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -85,3 +87,85 @@ func TestReplace(t *testing.T) {
 		}
 	})
 }
+
+func TestEditModes(t *testing.T) {
+	cfg := testConfig(t)
+	e := NewEditTool(cfg)
+	p := filepath.Join(cfg.RootDir, "m.txt")
+	run := func(args map[string]interface{}) (*Result, string) {
+		args["path"] = "m.txt"
+		res := e.Execute(testCtx(cfg, args))
+		data, _ := os.ReadFile(p)
+		return res, string(data)
+	}
+
+	t.Run("replace line range", func(t *testing.T) {
+		os.WriteFile(p, []byte("a\nb\nc\nd\n"), 0644)
+		res, got := run(map[string]interface{}{"mode": "lines", "start_line": float64(2), "end_line": float64(3), "new_string": "X\nY\nZ"})
+		if res.Status != "success" || got != "a\nX\nY\nZ\nd\n" {
+			t.Errorf("got %q, err %s", got, res.Error)
+		}
+	})
+
+	t.Run("delete lines with empty new_string", func(t *testing.T) {
+		os.WriteFile(p, []byte("a\nb\nc\n"), 0644)
+		_, got := run(map[string]interface{}{"mode": "lines", "start_line": float64(2), "new_string": ""})
+		if got != "a\nc\n" {
+			t.Errorf("got %q", got)
+		}
+	})
+
+	t.Run("line range out of bounds", func(t *testing.T) {
+		os.WriteFile(p, []byte("a\n"), 0644)
+		res, _ := run(map[string]interface{}{"mode": "lines", "start_line": float64(5), "new_string": "x"})
+		if res.Status != "error" {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("insert at top", func(t *testing.T) {
+		os.WriteFile(p, []byte("package x\n"), 0644)
+		_, got := run(map[string]interface{}{"mode": "insert", "line": float64(0), "new_string": "// header\n"})
+		if got != "// header\npackage x\n" {
+			t.Errorf("got %q", got)
+		}
+	})
+
+	t.Run("insert before anchor", func(t *testing.T) {
+		os.WriteFile(p, []byte("import (\n\t\"fmt\"\n)\n"), 0644)
+		_, got := run(map[string]interface{}{"mode": "insert", "anchor": `^\)`, "position": "before", "new_string": "\t\"os\""})
+		if got != "import (\n\t\"fmt\"\n\t\"os\"\n)\n" {
+			t.Errorf("got %q", got)
+		}
+	})
+
+	t.Run("ambiguous anchor rejected", func(t *testing.T) {
+		os.WriteFile(p, []byte("x\nx\n"), 0644)
+		res, _ := run(map[string]interface{}{"mode": "insert", "anchor": "x", "new_string": "y"})
+		if res.Status != "error" {
+			t.Error("expected error for ambiguous anchor")
+		}
+	})
+
+	t.Run("regex with capture groups", func(t *testing.T) {
+		os.WriteFile(p, []byte("oldName(1)\noldName(2)\n"), 0644)
+		res, got := run(map[string]interface{}{"mode": "regex", "pattern": `oldName\((\d)\)`, "new_string": "newName($1)", "expected_count": float64(2)})
+		if res.Status != "success" || got != "newName(1)\nnewName(2)\n" {
+			t.Errorf("got %q, err %s", got, res.Error)
+		}
+	})
+
+	t.Run("regex expected_count mismatch leaves file untouched", func(t *testing.T) {
+		os.WriteFile(p, []byte("foo foo\n"), 0644)
+		res, got := run(map[string]interface{}{"mode": "regex", "pattern": "foo", "new_string": "bar", "expected_count": float64(1)})
+		if res.Status != "error" || got != "foo foo\n" {
+			t.Errorf("expected error and unchanged file, got %q", got)
+		}
+	})
+
+	t.Run("unknown mode rejected", func(t *testing.T) {
+		if err := e.Validate(map[string]interface{}{"path": "m.txt", "mode": "bogus"}); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
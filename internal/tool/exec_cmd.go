@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/afumu/openlink/internal/security"
+	"github.com/afumu/openlink/internal/skill"
 	"github.com/afumu/openlink/internal/types"
 )
 
@@ -32,6 +33,7 @@ func (t *ExecCmdTool) Description() string {
 func (t *ExecCmdTool) Parameters() interface{} {
 	return map[string]string{
 		"command": "string (required) - shell command to execute",
+		"skill":   "string (optional) - run inside this skill's directory, for scripts bundled with the skill",
 	}
 }
 
@@ -77,6 +79,17 @@ func (t *ExecCmdTool) Execute(ctx *Context) *Result {
 	shell, flag := getShell()
 	proc := exec.CommandContext(execCtx, shell, flag, cmd)
 	proc.Dir = t.config.RootDir
+	// 运行 skill 自带的脚本：工作目录切换到 skill 目录，其余限制与普通命令相同
+	if name, _ := ctx.Args["skill"].(string); name != "" {
+		info, ok := skill.For(t.config).Get(name)
+		if !ok {
+			result.Status = "error"
+			result.Error = fmt.Sprintf("skill %q not found", name)
+			return result
+		}
+		proc.Dir = info.Dir
+		proc.Env = append(os.Environ(), "OPENLINK_SKILL_DIR="+info.Dir, "OPENLINK_ROOT_DIR="+t.config.RootDir)
+	}
 	output, err := proc.CombinedOutput()
 	result.EndTime = time.Now()
 
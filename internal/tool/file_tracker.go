@@ -0,0 +1,131 @@
+package tool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// staleExcerptLines 是文件过期时随错误返回的最新内容行数。
+const staleExcerptLines = 40
+
+type fileStamp struct {
+	hash    string
+	modTime time.Time
+}
+
+// FileTracker 按会话记录每个文件最近一次被读取时的内容哈希与修改时间，
+// 用于拒绝基于过期内容的写入和编辑。
+type FileTracker struct {
+	mu       sync.Mutex
+	sessions map[string]map[string]fileStamp
+}
+
+func NewFileTracker() *FileTracker {
+	return &FileTracker{sessions: make(map[string]map[string]fileStamp)}
+}
+
+// Record 记录 session 对 path 的最新已知状态（读取或写入之后调用）。
+func (ft *FileTracker) Record(session, path, hash string, modTime time.Time) {
+	if ft == nil {
+		return
+	}
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	files, ok := ft.sessions[session]
+	if !ok {
+		files = make(map[string]fileStamp)
+		ft.sessions[session] = files
+	}
+	files[path] = fileStamp{hash: hash, modTime: modTime}
+}
+
+func (ft *FileTracker) lookup(session, path string) (fileStamp, bool) {
+	if ft == nil {
+		return fileStamp{}, false
+	}
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	st, ok := ft.sessions[session][path]
+	return st, ok
+}
+
+// Check 在修改 path 之前调用：expectedHash 非空时要求当前内容哈希与之一致；
+// 否则若该会话读过此文件，则要求文件自那以后未被修改。
+// 从未读过的文件与尚不存在的文件直接放行。
+func (ft *FileTracker) Check(session, path, expectedHash string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			if expectedHash != "" {
+				return fmt.Errorf("expected_hash given but %s does not exist", path)
+			}
+			return nil
+		}
+		return err
+	}
+
+	st, tracked := ft.lookup(session, path)
+	if expectedHash == "" && (!tracked || info.ModTime().Equal(st.modTime)) {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	current := contentHash(data)
+	if expectedHash != "" {
+		if !strings.EqualFold(expectedHash, current) {
+			return staleError(path, fmt.Sprintf("content hash is %s, expected %s", current, expectedHash), data, current)
+		}
+		return nil
+	}
+	if current != st.hash {
+		return staleError(path, "file was modified since it was last read", data, current)
+	}
+	return nil
+}
+
+// contentHash 返回内容的短哈希（sha256 前 16 位十六进制）。
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+func staleError(path, reason string, data []byte, hash string) error {
+	lines := strings.Split(normalizeLineEndings(string(data)), "\n")
+	more := ""
+	if len(lines) > staleExcerptLines {
+		lines = lines[:staleExcerptLines]
+		more = "\n..."
+	}
+	return fmt.Errorf("refusing to modify %s: %s. Re-read the file with read_file before editing.\n[current hash: %s]\n%s%s",
+		path, reason, hash, strings.Join(lines, "\n"), more)
+}
+
+// checkFresh 使用上下文中的会话与 expected_hash 参数检查文件是否过期。
+func checkFresh(ctx *Context, path string) error {
+	expected, _ := ctx.Args["expected_hash"].(string)
+	return ctx.Files.Check(ctx.Session, path, expected)
+}
+
+// recordWrite 在成功写入后更新会话记录，使后续编辑无需重新读取。
+func recordWrite(ctx *Context, path string) {
+	if ctx.Files == nil {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	ctx.Files.Record(ctx.Session, path, contentHash(data), info.ModTime())
+}
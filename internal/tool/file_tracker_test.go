@@ -0,0 +1,80 @@
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStaleReadProtection(t *testing.T) {
+	cfg := testConfig(t)
+	files := NewFileTracker()
+	ctx := func(args map[string]interface{}) *Context {
+		return &Context{Args: args, Config: cfg, Session: "s1", Files: files}
+	}
+	p := filepath.Join(cfg.RootDir, "f.txt")
+	os.WriteFile(p, []byte("one\n"), 0644)
+
+	r := NewReadFileTool(cfg)
+	e := NewEditTool(cfg)
+	w := NewWriteFileTool(cfg)
+
+	res := r.Execute(ctx(map[string]interface{}{"path": "f.txt"}))
+	m := regexp.MustCompile(`\[hash: ([0-9a-f]+)\]`).FindStringSubmatch(res.Output)
+	if m == nil {
+		t.Fatalf("expected hash in read output, got %q", res.Output)
+	}
+	readHash := m[1]
+
+	t.Run("edit after read succeeds and keeps tracking", func(t *testing.T) {
+		res := e.Execute(ctx(map[string]interface{}{"path": "f.txt", "old_string": "one", "new_string": "two"}))
+		if res.Status != "success" {
+			t.Fatalf("edit failed: %s", res.Error)
+		}
+		res = e.Execute(ctx(map[string]interface{}{"path": "f.txt", "old_string": "two", "new_string": "three"}))
+		if res.Status != "success" {
+			t.Fatalf("second edit failed: %s", res.Error)
+		}
+	})
+
+	t.Run("external change refuses edit with fresh excerpt", func(t *testing.T) {
+		os.WriteFile(p, []byte("changed in IDE\n"), 0644)
+		os.Chtimes(p, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+		res := e.Execute(ctx(map[string]interface{}{"path": "f.txt", "old_string": "changed", "new_string": "x"}))
+		if res.Status != "error" || !strings.Contains(res.Error, "changed in IDE") {
+			t.Fatalf("expected stale error with excerpt, got %s / %q", res.Status, res.Error)
+		}
+		res = w.Execute(ctx(map[string]interface{}{"path": "f.txt", "content": "clobber"}))
+		if res.Status != "error" {
+			t.Error("expected write_file to be refused")
+		}
+	})
+
+	t.Run("other sessions are unaffected", func(t *testing.T) {
+		c := ctx(map[string]interface{}{"path": "f.txt", "old_string": "changed", "new_string": "edited"})
+		c.Session = "s2"
+		if res := e.Execute(c); res.Status != "success" {
+			t.Errorf("expected success for untracked session, got %s", res.Error)
+		}
+	})
+
+	t.Run("expected_hash mismatch refuses write", func(t *testing.T) {
+		c := ctx(map[string]interface{}{"path": "f.txt", "content": "x", "expected_hash": readHash})
+		c.Session = "s3"
+		if res := w.Execute(c); res.Status != "error" {
+			t.Error("expected error for mismatched expected_hash")
+		}
+	})
+
+	t.Run("re-read clears staleness", func(t *testing.T) {
+		res := r.Execute(ctx(map[string]interface{}{"path": "f.txt"}))
+		hash := regexp.MustCompile(`\[hash: ([0-9a-f]+)\]`).FindStringSubmatch(res.Output)[1]
+		res = w.Execute(ctx(map[string]interface{}{"path": "f.txt", "content": "fresh", "expected_hash": hash}))
+		if res.Status != "success" {
+			t.Errorf("expected success after re-read, got %s", res.Error)
+		}
+	})
+}
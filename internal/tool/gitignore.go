@@ -0,0 +1,117 @@
+package tool
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule 是 .gitignore 中的一条规则，base 为其所在目录（绝对路径，/ 分隔）。
+type ignoreRule struct {
+	base    string
+	segs    []string
+	negate  bool
+	dirOnly bool
+}
+
+// gitIgnore 按目录层级累积 .gitignore 规则；withDir 返回新实例，父目录的规则集不受影响，
+// 便于并发遍历时按分支共享。与 rg 一致，只有在 git 仓库内 .gitignore 才生效。
+type gitIgnore struct {
+	rules  []ignoreRule
+	inRepo bool
+}
+
+// loadGitIgnore 加载 dir 的祖先目录中（直到包含 .git 的仓库根目录）的 .gitignore，
+// 使在子目录中搜索时与 rg 的行为一致；dir 不在仓库内时不加载任何规则。
+func loadGitIgnore(dir string) *gitIgnore {
+	var ancestors []string
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return (&gitIgnore{}).withDir(dir)
+	}
+	for d := filepath.Dir(dir); ; d = filepath.Dir(d) {
+		ancestors = append(ancestors, d)
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			break
+		}
+		if filepath.Dir(d) == d {
+			ancestors = nil
+			break
+		}
+	}
+	g := &gitIgnore{}
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		g = g.withDir(ancestors[i])
+	}
+	return g.withDir(dir)
+}
+
+// withDir 读取 dir/.gitignore 并返回追加了其规则的新实例；没有规则时返回自身。
+// 尚未进入仓库时，dir 含 .git 才开始读取规则。
+func (g *gitIgnore) withDir(dir string) *gitIgnore {
+	if !g.inRepo {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+			return g
+		}
+		g = &gitIgnore{inRepo: true}
+	}
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return g
+	}
+	defer f.Close()
+
+	base := filepath.ToSlash(dir)
+	var added []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, "\\")
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		// 仅在开头或中间含 / 的模式相对 .gitignore 所在目录锚定，否则匹配任意层级
+		if !strings.Contains(line, "/") {
+			line = "**/" + line
+		}
+		r.segs = strings.Split(strings.TrimPrefix(line, "/"), "/")
+		added = append(added, r)
+	}
+	if len(added) == 0 {
+		return g
+	}
+	rules := make([]ignoreRule, 0, len(g.rules)+len(added))
+	rules = append(rules, g.rules...)
+	return &gitIgnore{rules: append(rules, added...), inRepo: true}
+}
+
+// Ignored 判断绝对路径 p 是否被忽略；后出现的规则优先，! 规则可取消忽略。
+func (g *gitIgnore) Ignored(p string, isDir bool) bool {
+	p = filepath.ToSlash(p)
+	ignored := false
+	for _, r := range g.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if !strings.HasPrefix(p, r.base+"/") {
+			continue
+		}
+		rel := strings.Split(p[len(r.base)+1:], "/")
+		if matchSegments(r.segs, rel, false, true) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/afumu/openlink/internal/security"
 	"github.com/afumu/openlink/internal/types"
+	"github.com/afumu/openlink/internal/walk"
 )
 
 type GlobTool struct {
@@ -21,12 +22,19 @@ func NewGlobTool(config *types.Config) *GlobTool {
 	return &GlobTool{config: config}
 }
 
-func (t *GlobTool) Name() string        { return "glob" }
-func (t *GlobTool) Description() string { return "Find files matching a glob pattern" }
+func (t *GlobTool) Name() string { return "glob" }
+func (t *GlobTool) Description() string {
+	return "Find files matching a glob pattern (** spans directories, {a,b} alternatives)"
+}
 func (t *GlobTool) Parameters() interface{} {
 	return map[string]string{
-		"pattern": "string (required) - glob pattern, e.g. **/*.go or *.ts",
-		"path":    "string (optional) - directory to search in (default: root)",
+		"pattern":         "string (required) - glob pattern, e.g. src/**/test/*.go or *.{ts,tsx}; patterns without / match file names at any depth",
+		"path":            "string (optional) - directory to search in (default: root)",
+		"exclude":         "string or array (optional) - glob pattern(s) to exclude; matching directories are not descended into",
+		"hidden":          "bool (optional) - include hidden files and directories (default false)",
+		"include_ignored": "bool (optional) - search vendor, VCS and build directories (node_modules, dist, ...) that are skipped by default; patterns naming such a directory literally, e.g. dist/**/*.js, search it anyway",
+		"sort":            "string (optional) - 'mtime' (newest first, default) or 'path'",
+		"limit":           "number (optional) - max results to return (default 100, max 1000)",
 	}
 }
 
@@ -34,16 +42,34 @@ func (t *GlobTool) Validate(args map[string]interface{}) error {
 	if p, ok := args["pattern"].(string); !ok || p == "" {
 		return errors.New("pattern is required")
 	}
+	if s, _ := args["sort"].(string); s != "" && s != "mtime" && s != "path" {
+		return errors.New("sort must be 'mtime' or 'path'")
+	}
 	return nil
 }
 
+const (
+	defaultGlobLimit = 100
+	maxGlobLimit     = 1000
+)
+
 func (t *GlobTool) Execute(ctx *Context) *Result {
 	result := &Result{StartTime: time.Now()}
 	pattern, _ := ctx.Args["pattern"].(string)
 	searchPath, _ := ctx.Args["path"].(string)
+	hidden := boolArg(ctx.Args, "hidden")
+	includeIgnored := boolArg(ctx.Args, "include_ignored")
+	sortBy, _ := ctx.Args["sort"].(string)
 	if searchPath == "" {
 		searchPath = "."
 	}
+	limit := defaultGlobLimit
+	if v, ok := intArg(ctx.Args, "limit"); ok && v >= 1 {
+		limit = v
+		if limit > maxGlobLimit {
+			limit = maxGlobLimit
+		}
+	}
 
 	var safePath string
 	var err error
@@ -58,47 +84,71 @@ func (t *GlobTool) Execute(ctx *Context) *Result {
 		return result
 	}
 
+	include := newGlobMatcher([]string{pattern}, hidden)
+	var exclude *globMatcher
+	if ex := stringListArg(ctx.Args, "exclude"); len(ex) > 0 {
+		exclude = newGlobMatcher(ex, true)
+	}
+
 	type fileEntry struct {
 		path  string
 		mtime time.Time
 	}
 	var files []fileEntry
 
-	basePat := filepath.Base(pattern)
-	isRecursive := strings.Contains(pattern, "**")
-
-	filepath.WalkDir(safePath, func(p string, d fs.DirEntry, err error) error {
-		if err != nil || d.IsDir() {
-			return nil
-		}
-		name := d.Name()
-		var matched bool
-		if isRecursive {
-			matched, _ = filepath.Match(basePat, name)
-		} else {
+	// walkFrom 从 start 开始遍历，路径始终相对 safePath 匹配
+	walkFrom := func(start string, include *globMatcher) {
+		filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if p == safePath {
+				return nil
+			}
 			rel, _ := filepath.Rel(safePath, p)
-			matched, _ = filepath.Match(pattern, rel)
-			if !matched {
-				matched, _ = filepath.Match(basePat, name)
+			rel = filepath.ToSlash(rel)
+			if d.IsDir() {
+				if p == start {
+					return nil
+				}
+				skipped := walk.SkipDir(d.Name()) && !includeIgnored && !include.NamesSegment(d.Name())
+				if skipped || (exclude != nil && exclude.Match(rel)) || !include.CouldMatchUnder(rel) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !include.Match(rel) || (exclude != nil && exclude.Match(rel)) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
 			}
-		}
-		if matched {
-			info, _ := d.Info()
 			files = append(files, fileEntry{
 				path:  filepath.ToSlash(p),
 				mtime: info.ModTime(),
 			})
-		}
-		return nil
-	})
+			return nil
+		})
+	}
+	walkFrom(safePath, include)
+	// web_crawl 的文档位于隐藏目录下，搜索整个工作目录时一并列出
+	if docs, ok := docsSearchRoot(ctx.Config.RootDir, safePath); ok && !hidden {
+		walkFrom(docs, newGlobMatcher([]string{pattern}, true))
+	}
 
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].mtime.After(files[j].mtime)
-	})
+	if sortBy == "path" {
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].path < files[j].path
+		})
+	} else {
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].mtime.After(files[j].mtime)
+		})
+	}
 
-	const limit = 100
-	truncated := len(files) > limit
-	if truncated {
+	total := len(files)
+	if total > limit {
 		files = files[:limit]
 	}
 
@@ -106,8 +156,8 @@ func (t *GlobTool) Execute(ctx *Context) *Result {
 	for _, f := range files {
 		lines = append(lines, f.path)
 	}
-	if truncated {
-		lines = append(lines, fmt.Sprintf("(结果已截断，仅显示前 %d 条)", limit))
+	if total > limit {
+		lines = append(lines, fmt.Sprintf("(结果已截断，共 %d 个匹配，仅显示前 %d 条)", total, limit))
 	}
 
 	result.Status = "success"
@@ -0,0 +1,93 @@
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGlobMatcher(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		hidden  bool
+		want    bool
+	}{
+		{"src/**/test/*.go", "src/a/b/test/x.go", false, true},
+		{"src/**/test/*.go", "src/test/x.go", false, true},
+		{"src/**/test/*.go", "src/a/x.go", false, false},
+		{"src/**/test/*.go", "lib/test/x.go", false, false},
+		{"*.go", "deep/dir/main.go", false, true},
+		{"*.{ts,tsx}", "web/app.tsx", false, true},
+		{"*.{ts,tsx}", "web/app.js", false, false},
+		{"{cmd,internal}/**/*.go", "internal/tool/glob.go", false, true},
+		{"**/*.yml", ".github/ci.yml", false, false},
+		{"**/*.yml", ".github/ci.yml", true, true},
+		{".github/**/*.yml", ".github/workflows/ci.yml", false, true},
+		{"*", ".env", false, false},
+	}
+	for _, c := range cases {
+		if got := newGlobMatcher([]string{c.pattern}, c.hidden).Match(c.path); got != c.want {
+			t.Errorf("match(%q, %q, hidden=%v) = %v, want %v", c.pattern, c.path, c.hidden, got, c.want)
+		}
+	}
+
+	m := newGlobMatcher([]string{"src/**/test/*.go"}, false)
+	if !m.CouldMatchUnder("src/a") || m.CouldMatchUnder("lib") {
+		t.Error("unexpected pruning decision")
+	}
+}
+
+func TestGlobToolOptions(t *testing.T) {
+	cfg := testConfig(t)
+	for _, p := range []string{
+		"src/a/test/x.go", "src/a/y.go", "src/gen/z.go",
+		"node_modules/pkg/index.go", ".git/hooks/h.go", "b.go",
+	} {
+		full := filepath.Join(cfg.RootDir, p)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(""), 0644)
+	}
+	g := NewGlobTool(cfg)
+
+	t.Run("segment-aware doublestar", func(t *testing.T) {
+		res := g.Execute(testCtx(cfg, map[string]interface{}{"pattern": "src/**/test/*.go"}))
+		if !strings.Contains(res.Output, "src/a/test/x.go") || strings.Contains(res.Output, "y.go") {
+			t.Errorf("got %q", res.Output)
+		}
+	})
+
+	t.Run("skips vendor and vcs dirs", func(t *testing.T) {
+		res := g.Execute(testCtx(cfg, map[string]interface{}{"pattern": "**/*.go", "hidden": true}))
+		if strings.Contains(res.Output, "node_modules") || strings.Contains(res.Output, ".git/") {
+			t.Errorf("got %q", res.Output)
+		}
+	})
+
+	t.Run("pattern naming a skipped dir searches it", func(t *testing.T) {
+		res := g.Execute(testCtx(cfg, map[string]interface{}{"pattern": "node_modules/**/*.go"}))
+		if !strings.Contains(res.Output, "node_modules/pkg/index.go") {
+			t.Errorf("got %q", res.Output)
+		}
+		res = g.Execute(testCtx(cfg, map[string]interface{}{"pattern": "index.go", "include_ignored": true}))
+		if !strings.Contains(res.Output, "node_modules/pkg/index.go") {
+			t.Errorf("include_ignored: got %q", res.Output)
+		}
+	})
+
+	t.Run("exclude prunes directories", func(t *testing.T) {
+		res := g.Execute(testCtx(cfg, map[string]interface{}{"pattern": "*.go", "exclude": []interface{}{"gen"}}))
+		if strings.Contains(res.Output, "z.go") || !strings.Contains(res.Output, "y.go") {
+			t.Errorf("got %q", res.Output)
+		}
+	})
+
+	t.Run("limit reports true total", func(t *testing.T) {
+		res := g.Execute(testCtx(cfg, map[string]interface{}{"pattern": "*.go", "limit": "1", "sort": "path"}))
+		lines := strings.Split(res.Output, "\n")
+		if len(lines) != 2 || !strings.HasSuffix(lines[0], "/b.go") || !strings.Contains(lines[1], "共 4 个匹配") {
+			t.Errorf("got %q", res.Output)
+		}
+	})
+}
@@ -2,9 +2,11 @@ package tool
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -15,6 +17,7 @@ import (
 
 	"github.com/afumu/openlink/internal/security"
 	"github.com/afumu/openlink/internal/types"
+	"github.com/afumu/openlink/internal/walk"
 )
 
 type GrepTool struct {
@@ -29,9 +32,17 @@ func (t *GrepTool) Name() string        { return "grep" }
 func (t *GrepTool) Description() string { return "Search file contents using regex" }
 func (t *GrepTool) Parameters() interface{} {
 	return map[string]string{
-		"pattern": "string (required) - regex pattern to search",
-		"path":    "string (optional) - directory to search (default: root)",
-		"include": "string (optional) - file glob filter, e.g. *.go",
+		"pattern":       "string (required) - regex pattern to search",
+		"path":          "string (optional) - directory to search (default: root)",
+		"include":       "string (optional) - file glob filter, e.g. *.go",
+		"output_mode":   "string (optional) - 'content' (default, matching lines), 'files_with_matches' or 'count'",
+		"-A":            "number (optional) - lines of context after each match (content mode)",
+		"-B":            "number (optional) - lines of context before each match (content mode)",
+		"-C":            "number (optional) - lines of context before and after each match (content mode)",
+		"-i":            "bool (optional) - case-insensitive search",
+		"fixed_strings": "bool (optional) - treat pattern as a literal string instead of a regex",
+		"multiline":     "bool (optional) - allow matches to span lines (use (?s) to let . match newlines)",
+		"max_count":     "number (optional) - max matching lines per file",
 	}
 }
 
@@ -42,18 +53,94 @@ func (t *GrepTool) Validate(args map[string]interface{}) error {
 	if inc, ok := args["include"].(string); ok && strings.ContainsAny(inc, "/\\") {
 		return errors.New("include pattern must not contain path separators")
 	}
+	switch m, _ := args["output_mode"].(string); m {
+	case "", grepModeContent, grepModeFiles, grepModeCount:
+	default:
+		return errors.New("output_mode must be 'content', 'files_with_matches' or 'count'")
+	}
 	return nil
 }
 
+const (
+	grepModeContent = "content"
+	grepModeFiles   = "files_with_matches"
+	grepModeCount   = "count"
+
+	grepLimit = 100
+)
+
+// grepOptions 是 rg 与原生实现共用的搜索选项。
+type grepOptions struct {
+	pattern    string
+	include    string
+	ignoreCase bool
+	fixed      bool
+	multiline  bool
+	before     int
+	after      int
+	maxPerFile int
+	mode       string
+}
+
+// expr 返回按选项调整后的正则表达式源码。
+func (o *grepOptions) expr() string {
+	expr := o.pattern
+	if o.fixed {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if o.ignoreCase {
+		expr = "(?i)" + expr
+	}
+	return expr
+}
+
+// regexp 按选项编译原生实现使用的正则。
+func (o *grepOptions) regexp() (*regexp.Regexp, error) {
+	re, err := regexp.Compile(o.expr())
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re, nil
+}
+
+// grepFile 是一个文件的搜索结果：命中的行号（1-based，升序）及文件修改时间。
+type grepFile struct {
+	path    string
+	mtime   time.Time
+	matches []int
+}
+
 func (t *GrepTool) Execute(ctx *Context) *Result {
 	result := &Result{StartTime: time.Now()}
-	pattern, _ := ctx.Args["pattern"].(string)
 	searchPath, _ := ctx.Args["path"].(string)
-	include, _ := ctx.Args["include"].(string)
 	if searchPath == "" {
 		searchPath = "."
 	}
 
+	opts := &grepOptions{
+		ignoreCase: boolArg(ctx.Args, "-i"),
+		fixed:      boolArg(ctx.Args, "fixed_strings"),
+		multiline:  boolArg(ctx.Args, "multiline"),
+	}
+	opts.pattern, _ = ctx.Args["pattern"].(string)
+	opts.include, _ = ctx.Args["include"].(string)
+	opts.mode, _ = ctx.Args["output_mode"].(string)
+	if opts.mode == "" {
+		opts.mode = grepModeContent
+	}
+	if n, ok := intArg(ctx.Args, "-C"); ok && n > 0 {
+		opts.before, opts.after = n, n
+	}
+	if n, ok := intArg(ctx.Args, "-B"); ok && n >= 0 {
+		opts.before = n
+	}
+	if n, ok := intArg(ctx.Args, "-A"); ok && n >= 0 {
+		opts.after = n
+	}
+	if n, ok := intArg(ctx.Args, "max_count"); ok && n > 0 {
+		opts.maxPerFile = n
+	}
+
 	var safePath string
 	var err error
 	if filepath.IsAbs(searchPath) {
@@ -67,111 +154,305 @@ func (t *GrepTool) Execute(ctx *Context) *Result {
 		return result
 	}
 
-	var output string
-	if rgPath, err := exec.LookPath("rg"); err == nil {
-		output = grepWithRg(rgPath, pattern, safePath, include)
-	} else {
-		output, err = grepNative(pattern, safePath, include)
+	// 先用 Go 正则校验，保证两种实现对非法模式的报错一致
+	if _, err := opts.regexp(); err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+
+	roots := []string{safePath}
+	if docs, ok := docsSearchRoot(ctx.Config.RootDir, safePath); ok {
+		roots = append(roots, docs)
+	}
+	rgPath, rgErr := exec.LookPath("rg")
+	var files []grepFile
+	for _, root := range roots {
+		var found []grepFile
+		if rgErr == nil {
+			found, err = grepWithRg(rgPath, root, opts)
+		} else {
+			found, err = grepNative(root, opts)
+		}
 		if err != nil {
 			result.Status = "error"
 			result.Error = err.Error()
 			return result
 		}
+		files = append(files, found...)
 	}
 
 	result.Status = "success"
-	result.Output = output
+	result.Output = formatGrepResults(files, opts, workspaceRoot(ctx.Config.RootDir))
 	result.EndTime = time.Now()
 	return result
 }
 
-func grepWithRg(rgPath, pattern, searchPath, include string) string {
-	args := []string{"-n", "--no-heading"}
-	if include != "" {
-		if strings.ContainsAny(include, "/\\") {
-			return "error: include pattern must not contain path separators"
+func grepWithRg(rgPath, searchPath string, opts *grepOptions) ([]grepFile, error) {
+	args := []string{"--json"}
+	if opts.ignoreCase {
+		args = append(args, "-i")
+	}
+	if opts.fixed {
+		args = append(args, "-F")
+	}
+	if opts.multiline {
+		args = append(args, "-U")
+	}
+	if opts.maxPerFile > 0 {
+		args = append(args, "-m", fmt.Sprint(opts.maxPerFile))
+	}
+	if opts.include != "" {
+		args = append(args, "--glob", opts.include)
+	}
+	for _, dir := range walk.SkipDirNames() {
+		args = append(args, "--glob", "!"+dir)
+	}
+	args = append(args, "--", opts.pattern, searchPath)
+	out, err := exec.Command(rgPath, args...).Output()
+	if err != nil {
+		// 退出码 1 表示没有匹配；其他错误（如个别文件不可读）仍尽量使用已有输出
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, err
 		}
-		args = append(args, "--glob", include)
 	}
-	args = append(args, "--", pattern, searchPath)
-	cmd := exec.Command(rgPath, args...)
-	out, _ := cmd.Output()
-	lines := strings.Split(strings.ReplaceAll(string(out), "\r\n", "\n"), "\n")
-	return formatGrepLines(lines, 100)
+	return parseRgJSON(out)
 }
 
-func grepNative(pattern, searchPath, include string) (string, error) {
-	re, err := regexp.Compile(pattern)
-	if err != nil {
-		return "", fmt.Errorf("invalid pattern: %w", err)
-	}
+// rgText 对应 rg --json 中的 {"text": ...} 或 {"bytes": base64} 字段。
+type rgText struct {
+	Text  *string `json:"text"`
+	Bytes string  `json:"bytes"`
+}
 
-	type match struct {
-		line  string
-		mtime time.Time
+func (t rgText) String() string {
+	if t.Text != nil {
+		return *t.Text
 	}
-	var matches []match
+	b, _ := base64.StdEncoding.DecodeString(t.Bytes)
+	return string(b)
+}
 
-	filepath.WalkDir(searchPath, func(p string, d fs.DirEntry, err error) error {
-		if err != nil || d.IsDir() {
-			return nil
+func parseRgJSON(out []byte) ([]grepFile, error) {
+	byPath := map[string]*grepFile{}
+	var order []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var ev struct {
+			Type string `json:"type"`
+			Data struct {
+				Path       rgText `json:"path"`
+				Lines      rgText `json:"lines"`
+				LineNumber int    `json:"line_number"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil || ev.Type != "match" {
+			continue
 		}
-		if include != "" {
-			if ok, _ := filepath.Match(include, d.Name()); !ok {
-				return nil
+		p := ev.Data.Path.String()
+		f, ok := byPath[p]
+		if !ok {
+			f = &grepFile{path: p}
+			if info, err := os.Stat(p); err == nil {
+				f.mtime = info.ModTime()
 			}
+			byPath[p] = f
+			order = append(order, p)
+		}
+		// 多行匹配时 lines 跨越多行，展开为逐行命中
+		n := strings.Count(strings.TrimSuffix(ev.Data.Lines.String(), "\n"), "\n")
+		for i := 0; i <= n; i++ {
+			f.matches = appendLine(f.matches, ev.Data.LineNumber+i)
 		}
-		info, _ := d.Info()
-		mtime := info.ModTime()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	files := make([]grepFile, 0, len(order))
+	for _, p := range order {
+		files = append(files, *byPath[p])
+	}
+	return files, nil
+}
 
-		f, err := os.Open(p)
-		if err != nil {
-			return nil
-		}
-		defer f.Close()
-
-		lineNum := 0
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			lineNum++
-			text := scanner.Text()
-			if re.MatchString(text) {
-				matches = append(matches, match{
-					line:  fmt.Sprintf("%s:%d:%s", filepath.ToSlash(p), lineNum, text),
-					mtime: mtime,
-				})
+// matchLines 返回内容中命中的行号（1-based，升序），受 maxPerFile 限制。
+func matchLines(re *regexp.Regexp, data []byte, opts *grepOptions) []int {
+	var matches []int
+	if opts.multiline {
+		lineStarts := []int{0}
+		for i, b := range data {
+			if b == '\n' {
+				lineStarts = append(lineStarts, i+1)
 			}
 		}
-		return nil
-	})
+		lineOf := func(off int) int {
+			return sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > off })
+		}
+		for _, loc := range re.FindAllIndex(data, -1) {
+			end := loc[1]
+			if end > loc[0] {
+				end--
+			}
+			for l := lineOf(loc[0]); l <= lineOf(end); l++ {
+				matches = appendLine(matches, l)
+				if opts.maxPerFile > 0 && len(matches) >= opts.maxPerFile {
+					return matches
+				}
+			}
+		}
+		return matches
+	}
+	for i, line := range splitFileLines(data) {
+		if re.MatchString(line) {
+			matches = append(matches, i+1)
+			if opts.maxPerFile > 0 && len(matches) >= opts.maxPerFile {
+				break
+			}
+		}
+	}
+	return matches
+}
 
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].mtime.After(matches[j].mtime)
-	})
+// appendLine 追加行号并去重（输入按升序到达）。
+func appendLine(lines []int, n int) []int {
+	if len(lines) > 0 && lines[len(lines)-1] >= n {
+		return lines
+	}
+	return append(lines, n)
+}
 
-	lines := make([]string, len(matches))
-	for i, m := range matches {
-		lines[i] = m.line
+// splitFileLines 按行拆分文件内容，去掉行尾的 \r 与末尾空行。
+func splitFileLines(data []byte) []string {
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
 	}
-	return formatGrepLines(lines, 100), nil
+	return lines
 }
 
-func formatGrepLines(lines []string, limit int) string {
+// formatGrepResults 将两种实现的结果统一排序（修改时间倒序，其次路径）并按输出模式格式化。
+func formatGrepResults(files []grepFile, opts *grepOptions, root string) string {
+	sort.SliceStable(files, func(i, j int) bool {
+		if !files[i].mtime.Equal(files[j].mtime) {
+			return files[i].mtime.After(files[j].mtime)
+		}
+		return files[i].path < files[j].path
+	})
+
 	var out []string
 	count := 0
-	for _, l := range lines {
-		if l == "" {
-			continue
-		}
-		out = append(out, l)
-		count++
-		if count >= limit {
-			out = append(out, fmt.Sprintf("(结果已截断，仅显示前 %d 条)", limit))
+	truncated := false
+	for _, f := range files {
+		if count >= grepLimit {
+			truncated = true
 			break
 		}
+		rel := displayPath(root, f.path)
+		switch opts.mode {
+		case grepModeFiles:
+			out = append(out, rel)
+			count++
+		case grepModeCount:
+			out = append(out, fmt.Sprintf("%s:%d", rel, len(f.matches)))
+			count++
+		default:
+			data, err := os.ReadFile(f.path)
+			if err != nil {
+				continue
+			}
+			lines := splitFileLines(data)
+			matches := f.matches
+			if remaining := grepLimit - count; len(matches) > remaining {
+				matches = matches[:remaining]
+				truncated = true
+			}
+			if len(out) > 0 && (opts.before > 0 || opts.after > 0) {
+				out = append(out, "--")
+			}
+			out = append(out, contentLines(rel, lines, matches, opts.before, opts.after)...)
+			count += len(matches)
+		}
 	}
 	if len(out) == 0 {
 		return "No matches found"
 	}
+	if truncated {
+		out = append(out, fmt.Sprintf("(结果已截断，仅显示前 %d 条)", grepLimit))
+	}
 	return strings.Join(out, "\n")
 }
+
+// contentLines 输出命中行（path:line:text）及其上下文（path-line-text），不连续的片段之间用 -- 分隔。
+func contentLines(rel string, lines []string, matches []int, before, after int) []string {
+	isMatch := make(map[int]bool, len(matches))
+	for _, m := range matches {
+		isMatch[m] = true
+	}
+	var out []string
+	last := 0
+	for _, m := range matches {
+		start := m - before
+		if start <= last {
+			start = last + 1
+		}
+		if start < 1 {
+			start = 1
+		}
+		if last > 0 && start > last+1 {
+			out = append(out, "--")
+		}
+		end := m + after
+		if end > len(lines) {
+			end = len(lines)
+		}
+		for n := start; n <= end; n++ {
+			if n <= last {
+				continue
+			}
+			sep := "-"
+			if isMatch[n] {
+				sep = ":"
+			}
+			out = append(out, fmt.Sprintf("%s%s%d%s%s", rel, sep, n, sep, lines[n-1]))
+			last = n
+		}
+	}
+	return out
+}
+
+// workspaceRoot 返回解析过符号链接的工作目录，用于计算相对路径。
+func workspaceRoot(rootDir string) string {
+	if real, err := filepath.EvalSymlinks(rootDir); err == nil {
+		return real
+	}
+	return rootDir
+}
+
+// docsSearchRoot 在搜索整个工作目录时返回 web_crawl 的文档目录（存在时）：
+// 它位于隐藏的 .openlink 下，默认遍历会跳过，需要单独作为搜索起点。
+func docsSearchRoot(rootDir, searchPath string) (string, bool) {
+	root := workspaceRoot(rootDir)
+	if searchPath != root {
+		return "", false
+	}
+	docs := filepath.Join(root, DocsDir)
+	if fi, err := os.Stat(docs); err != nil || !fi.IsDir() {
+		return "", false
+	}
+	return docs, true
+}
+
+// displayPath 将路径显示为相对工作目录的形式；工作目录外的路径用 ~ 缩写家目录。
+func displayPath(root, p string) string {
+	if rel, err := filepath.Rel(root, p); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(rel)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, p); err == nil && !strings.HasPrefix(rel, "..") {
+			return "~/" + filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(p)
+}
@@ -0,0 +1,139 @@
+package tool
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/afumu/openlink/internal/walk"
+)
+
+const (
+	// maxGrepFileSize 以上的文件不搜索（通常是数据或生成文件）。
+	maxGrepFileSize = 10 << 20
+	// binarySniffLen 是判断二进制文件时检查的前缀长度，含 NUL 字节即视为二进制。
+	binarySniffLen = 8 << 10
+)
+
+// grepNative 是未安装 rg 时的搜索实现：单个 goroutine 遍历目录树（跳过隐藏文件、
+// 默认忽略目录和 .gitignore 命中项），多个 worker 并行读取与匹配文件。
+// 与 rg 一样搜索全部文件，再由 formatGrepResults 按修改时间排序后截断，结果不受 worker 调度影响。
+func grepNative(searchPath string, opts *grepOptions) ([]grepFile, error) {
+	re, err := opts.regexp()
+	if err != nil {
+		return nil, err
+	}
+	pre := grepPrefilter(opts)
+	info, err := os.Stat(searchPath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if f, ok := grepSearchFile(re, pre, searchPath, opts); ok {
+			return []grepFile{f}, nil
+		}
+		return nil, nil
+	}
+
+	jobs := make(chan string, 256)
+	var (
+		mu    sync.Mutex
+		files []grepFile
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				f, ok := grepSearchFile(re, pre, p, opts)
+				if !ok {
+					continue
+				}
+				mu.Lock()
+				files = append(files, f)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	grepWalk(searchPath, loadGitIgnore(searchPath), opts, jobs)
+	close(jobs)
+	wg.Wait()
+	return files, nil
+}
+
+// grepWalk 深度优先遍历 dir，把待搜索的文件送入 jobs。
+func grepWalk(dir string, ign *gitIgnore, opts *grepOptions, jobs chan<- string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if isHiddenName(name) {
+			continue
+		}
+		p := filepath.Join(dir, name)
+		if e.IsDir() {
+			if walk.SkipDir(name) || ign.Ignored(p, true) {
+				continue
+			}
+			grepWalk(p, ign.withDir(p), opts, jobs)
+			continue
+		}
+		if !e.Type().IsRegular() || ign.Ignored(p, false) {
+			continue
+		}
+		if opts.include != "" {
+			if ok, _ := filepath.Match(opts.include, name); !ok {
+				continue
+			}
+		}
+		jobs <- p
+	}
+}
+
+// grepPrefilter 返回在整个文件内容上执行的 (?m) 正则：逐行匹配前先整体判断一次，
+// 绝大多数不含匹配的文件无需拆行。多行模式或依赖 \A、\z 的模式不使用预过滤。
+func grepPrefilter(opts *grepOptions) *regexp.Regexp {
+	if opts.multiline || strings.Contains(opts.pattern, `\A`) || strings.Contains(opts.pattern, `\z`) {
+		return nil
+	}
+	pre, err := regexp.Compile("(?m)" + opts.expr())
+	if err != nil {
+		return nil
+	}
+	return pre
+}
+
+// grepSearchFile 搜索单个文件；过大、不可读或二进制文件直接跳过。
+func grepSearchFile(re, pre *regexp.Regexp, p string, opts *grepOptions) (grepFile, bool) {
+	info, err := os.Stat(p)
+	if err != nil || info.Size() > maxGrepFileSize {
+		return grepFile{}, false
+	}
+	data, err := os.ReadFile(p)
+	if err != nil || isBinary(data) {
+		return grepFile{}, false
+	}
+	if pre != nil && !pre.Match(data) {
+		return grepFile{}, false
+	}
+	matches := matchLines(re, data, opts)
+	if len(matches) == 0 {
+		return grepFile{}, false
+	}
+	return grepFile{path: p, mtime: info.ModTime(), matches: matches}, true
+}
+
+func isBinary(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
@@ -0,0 +1,218 @@
+package tool
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGrepOptions(t *testing.T) {
+	cfg := testConfig(t)
+	os.MkdirAll(filepath.Join(cfg.RootDir, "pkg"), 0755)
+	os.WriteFile(filepath.Join(cfg.RootDir, "pkg", "a.go"), []byte("one\nTwo\nthree\nfour\nfive\nsix\ntwo\n"), 0644)
+	os.WriteFile(filepath.Join(cfg.RootDir, "pkg", "b.txt"), []byte("a.b\naxb\n"), 0644)
+	g := NewGrepTool(cfg)
+	run := func(args map[string]interface{}) string {
+		t.Helper()
+		res := g.Execute(testCtx(cfg, args))
+		if res.Status != "success" {
+			t.Fatalf("grep failed: %s", res.Error)
+		}
+		return res.Output
+	}
+
+	t.Run("workspace-relative paths", func(t *testing.T) {
+		out := run(map[string]interface{}{"pattern": "three"})
+		if out != "pkg/a.go:3:three" {
+			t.Errorf("got %q", out)
+		}
+	})
+
+	t.Run("context lines", func(t *testing.T) {
+		out := run(map[string]interface{}{"pattern": "^four$", "-B": float64(1), "-A": "1"})
+		want := "pkg/a.go-3-three\npkg/a.go:4:four\npkg/a.go-5-five"
+		if out != want {
+			t.Errorf("got %q, want %q", out, want)
+		}
+	})
+
+	t.Run("separated context groups", func(t *testing.T) {
+		out := run(map[string]interface{}{"pattern": "one|six", "-C": float64(1)})
+		if !strings.Contains(out, "pkg/a.go-2-Two\n--\npkg/a.go-5-five") {
+			t.Errorf("got %q", out)
+		}
+	})
+
+	t.Run("case insensitive", func(t *testing.T) {
+		out := run(map[string]interface{}{"pattern": "two", "-i": true})
+		if !strings.Contains(out, ":2:Two") || !strings.Contains(out, ":7:two") {
+			t.Errorf("got %q", out)
+		}
+	})
+
+	t.Run("fixed strings", func(t *testing.T) {
+		out := run(map[string]interface{}{"pattern": "a.b", "fixed_strings": "true"})
+		if out != "pkg/b.txt:1:a.b" {
+			t.Errorf("got %q", out)
+		}
+	})
+
+	t.Run("files_with_matches and count", func(t *testing.T) {
+		if out := run(map[string]interface{}{"pattern": "o", "output_mode": "files_with_matches"}); out != "pkg/a.go" {
+			t.Errorf("got %q", out)
+		}
+		if out := run(map[string]interface{}{"pattern": "o", "output_mode": "count"}); out != "pkg/a.go:4" {
+			t.Errorf("got %q", out)
+		}
+	})
+
+	t.Run("max per file", func(t *testing.T) {
+		out := run(map[string]interface{}{"pattern": "o", "max_count": float64(2)})
+		if strings.Count(out, "\n") != 1 {
+			t.Errorf("got %q", out)
+		}
+	})
+
+	t.Run("multiline", func(t *testing.T) {
+		out := run(map[string]interface{}{"pattern": "five\nsix", "multiline": true})
+		if out != "pkg/a.go:5:five\npkg/a.go:6:six" {
+			t.Errorf("got %q", out)
+		}
+	})
+
+	t.Run("invalid output mode rejected", func(t *testing.T) {
+		if err := g.Validate(map[string]interface{}{"pattern": "x", "output_mode": "json"}); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestParseRgJSON(t *testing.T) {
+	out := []byte(`{"type":"begin","data":{"path":{"text":"/w/a.go"}}}
+{"type":"match","data":{"path":{"text":"/w/a.go"},"lines":{"text":"five\nsix\n"},"line_number":5}}
+{"type":"context","data":{"path":{"text":"/w/a.go"},"lines":{"text":"seven\n"},"line_number":7}}
+{"type":"match","data":{"path":{"bytes":"L3cvYi5nbw=="},"lines":{"text":"x\n"},"line_number":2}}
+`)
+	files, err := parseRgJSON(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].path != "/w/a.go" || files[1].path != "/w/b.go" {
+		t.Fatalf("unexpected files %+v", files)
+	}
+	if got := files[0].matches; len(got) != 2 || got[0] != 5 || got[1] != 6 {
+		t.Errorf("expected multiline match expanded to lines 5-6, got %v", got)
+	}
+}
+
+func TestGrepNativeEngine(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		p := filepath.Join(root, rel)
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte(content), 0644)
+	}
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	write(".gitignore", "*.log\ngen/\n!keep.log\n")
+	write("sub/.gitignore", "local.txt\n")
+	write("a.txt", "needle\n")
+	write("debug.log", "needle\n")
+	write("keep.log", "needle\n")
+	write("gen/out.txt", "needle\n")
+	write("sub/local.txt", "needle\n")
+	write("sub/b.txt", "needle\n")
+	write("bin.dat", "needle\x00\x01")
+	write("long.txt", strings.Repeat("x", 200*1024)+"needle\n")
+
+	files, err := grepNative(root, &grepOptions{pattern: "needle", mode: grepModeFiles})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, f := range files {
+		rel, _ := filepath.Rel(root, f.path)
+		got[filepath.ToSlash(rel)] = true
+	}
+	for _, want := range []string{"a.txt", "keep.log", "sub/b.txt", "long.txt"} {
+		if !got[want] {
+			t.Errorf("expected %s in results, got %v", want, got)
+		}
+	}
+	for _, skip := range []string{"debug.log", "gen/out.txt", "sub/local.txt", "bin.dat"} {
+		if got[skip] {
+			t.Errorf("expected %s to be skipped", skip)
+		}
+	}
+
+	t.Run("searches every file so truncation is deterministic", func(t *testing.T) {
+		dir := t.TempDir()
+		for i := 0; i < 500; i++ {
+			os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%03d.txt", i)), []byte("hit\n"), 0644)
+		}
+		opts := &grepOptions{pattern: "hit", mode: grepModeFiles}
+		first, _ := grepNative(dir, opts)
+		if len(first) != 500 {
+			t.Fatalf("expected all 500 files, got %d", len(first))
+		}
+		second, _ := grepNative(dir, opts)
+		if a, b := formatGrepResults(first, opts, dir), formatGrepResults(second, opts, dir); a != b {
+			t.Error("truncated output differs between runs")
+		}
+	})
+
+	t.Run(".gitignore outside a repository is ignored", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log\n"), 0644)
+		os.WriteFile(filepath.Join(dir, "app.log"), []byte("hit\n"), 0644)
+		files, _ := grepNative(dir, &grepOptions{pattern: "hit", mode: grepModeFiles})
+		if len(files) != 1 {
+			t.Errorf("expected app.log to be searched like rg does, got %d files", len(files))
+		}
+	})
+}
+
+// benchTree 生成一个包含大量源文件的目录树，用于比较原生实现与 rg 的性能。
+func benchTree(b *testing.B) string {
+	b.Helper()
+	root := b.TempDir()
+	line := "func handler(w http.ResponseWriter, r *http.Request) { return nil }\n"
+	body := strings.Repeat(line, 200)
+	for d := 0; d < 50; d++ {
+		dir := filepath.Join(root, fmt.Sprintf("pkg%02d", d))
+		os.MkdirAll(dir, 0755)
+		for f := 0; f < 100; f++ {
+			os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%03d.go", f)), []byte(body), 0644)
+		}
+	}
+	os.WriteFile(filepath.Join(root, "pkg25", "file050.go"), []byte(body+"needleXYZ\n"), 0644)
+	return root
+}
+
+func BenchmarkGrepNative(b *testing.B) {
+	root := benchTree(b)
+	opts := &grepOptions{pattern: "needleXYZ", mode: grepModeContent}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if files, _ := grepNative(root, opts); len(files) != 1 {
+			b.Fatalf("expected 1 file, got %d", len(files))
+		}
+	}
+}
+
+func BenchmarkGrepRg(b *testing.B) {
+	rg, err := exec.LookPath("rg")
+	if err != nil {
+		b.Skip("rg not installed")
+	}
+	root := benchTree(b)
+	opts := &grepOptions{pattern: "needleXYZ", mode: grepModeContent}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if files, _ := grepWithRg(rg, root, opts); len(files) != 1 {
+			b.Fatalf("expected 1 file, got %d", len(files))
+		}
+	}
+}
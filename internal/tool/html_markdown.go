@@ -0,0 +1,503 @@
+package tool
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// decodeHTMLBody 按 Content-Type 头、BOM 或 <meta charset> 将响应体转为 UTF-8（如 GBK 页面）。
+func decodeHTMLBody(body []byte, contentType string) string {
+	r, err := charset.NewReader(bytes.NewReader(body), contentType)
+	if err != nil {
+		return string(body)
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		return string(body)
+	}
+	return string(decoded)
+}
+
+var (
+	// 命中这些 class/id 的元素视为导航、广告、Cookie 提示等样板内容
+	boilerplateRe = regexp.MustCompile(`(?i)cookie|consent|gdpr|banner|navbar|\bnav\b|menu|sidebar|footer|comment|share|social|related|popup|modal|subscribe|newsletter|advert|\bads?\b|promo|breadcrumb|pagination|toolbar|skip-link`)
+	contentHintRe = regexp.MustCompile(`(?i)article|content|main|post|entry|body|text|story|markdown|doc`)
+	spaceRe       = regexp.MustCompile(`\s+`)
+	blankLinesRe  = regexp.MustCompile(`\n{3,}`)
+)
+
+// pageToMarkdown 解析 HTML，提取正文并转换为 Markdown；plain 为 true 时输出纯文本。
+func pageToMarkdown(src string, pageURL *url.URL, plain bool) string {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return strings.TrimSpace(src)
+	}
+	base := pageURL
+	if b := findElement(doc, atom.Base); b != nil {
+		if href := attr(b, "href"); href != "" && base != nil {
+			if u, err := base.Parse(href); err == nil {
+				base = u
+			}
+		}
+	}
+	title := pageTitle(doc)
+
+	removeBoilerplate(doc)
+	main := mainContent(doc)
+
+	r := &mdRenderer{base: base, plain: plain}
+	out := r.render(main)
+	out = tidyMarkdown(out)
+	if title != "" && !strings.Contains(out, title) {
+		if plain {
+			out = title + "\n\n" + out
+		} else {
+			out = "# " + title + "\n\n" + out
+		}
+	}
+	return strings.TrimSpace(out)
+}
+
+func pageTitle(doc *html.Node) string {
+	if t := findElement(doc, atom.Title); t != nil {
+		if s := strings.TrimSpace(spaceRe.ReplaceAllString(textContent(t), " ")); s != "" {
+			return s
+		}
+	}
+	var og string
+	walkNodes(doc, func(n *html.Node) bool {
+		if n.DataAtom == atom.Meta && attr(n, "property") == "og:title" {
+			og = strings.TrimSpace(attr(n, "content"))
+			return false
+		}
+		return true
+	})
+	return og
+}
+
+// removeBoilerplate 删除脚本、样式、隐藏元素以及导航/页脚/Cookie 横幅等非正文节点。
+// 表单只删除搜索、登录这类小表单；ASP.NET 等页面用 <form> 包住整个正文，这种表单保留。
+func removeBoilerplate(doc *html.Node) {
+	bodyText := len(strings.TrimSpace(textContent(doc)))
+	var remove []*html.Node
+	walkNodes(doc, func(n *html.Node) bool {
+		switch n.Type {
+		case html.CommentNode:
+			remove = append(remove, n)
+			return false
+		case html.ElementNode:
+		default:
+			return true
+		}
+		switch n.DataAtom {
+		case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Iframe, atom.Svg,
+			atom.Nav, atom.Aside, atom.Footer, atom.Button, atom.Select, atom.Input, atom.Dialog:
+			remove = append(remove, n)
+			return false
+		case atom.Form:
+			if len(strings.TrimSpace(textContent(n)))*2 < bodyText {
+				remove = append(remove, n)
+				return false
+			}
+		case atom.Header:
+			if !hasAncestor(n, atom.Article, atom.Main) {
+				remove = append(remove, n)
+				return false
+			}
+		case atom.Html, atom.Body, atom.Main, atom.Article:
+			return true
+		}
+		if _, hidden := attrOK(n, "hidden"); hidden || attr(n, "aria-hidden") == "true" ||
+			strings.Contains(strings.ReplaceAll(attr(n, "style"), " ", ""), "display:none") {
+			remove = append(remove, n)
+			return false
+		}
+		hint := attr(n, "class") + " " + attr(n, "id") + " " + attr(n, "role")
+		if boilerplateRe.MatchString(hint) && !contentHintRe.MatchString(hint) {
+			remove = append(remove, n)
+			return false
+		}
+		return true
+	})
+	for _, n := range remove {
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+		}
+	}
+}
+
+// mainContent 选择正文节点：优先唯一的 <main>/<article>/[role=main]，
+// 否则按 readability 的思路给段落的父节点打分，并按链接密度惩罚。
+func mainContent(doc *html.Node) *html.Node {
+	body := findElement(doc, atom.Body)
+	if body == nil {
+		body = doc
+	}
+	var landmarks []*html.Node
+	walkNodes(body, func(n *html.Node) bool {
+		if n.Type == html.ElementNode && (n.DataAtom == atom.Main || attr(n, "role") == "main") {
+			landmarks = append(landmarks, n)
+			return false
+		}
+		return true
+	})
+	if len(landmarks) == 0 {
+		walkNodes(body, func(n *html.Node) bool {
+			if n.DataAtom == atom.Article {
+				landmarks = append(landmarks, n)
+				return false
+			}
+			return true
+		})
+	}
+	if len(landmarks) == 1 && len(strings.TrimSpace(textContent(landmarks[0]))) >= 200 {
+		return landmarks[0]
+	}
+
+	scores := map[*html.Node]float64{}
+	walkNodes(body, func(n *html.Node) bool {
+		if n.DataAtom != atom.P && n.DataAtom != atom.Pre && n.DataAtom != atom.Td && n.DataAtom != atom.Blockquote {
+			return true
+		}
+		text := strings.TrimSpace(textContent(n))
+		if len(text) < 25 {
+			return false
+		}
+		score := 1 + float64(strings.Count(text, ",")+strings.Count(text, "，"))
+		if l := float64(len(text)) / 100; l < 3 {
+			score += l
+		} else {
+			score += 3
+		}
+		if p := n.Parent; p != nil {
+			scores[p] += score
+			if gp := p.Parent; gp != nil {
+				scores[gp] += score / 2
+			}
+		}
+		return false
+	})
+
+	var best *html.Node
+	bestScore := 0.0
+	for n, s := range scores {
+		hint := attr(n, "class") + " " + attr(n, "id")
+		if contentHintRe.MatchString(hint) {
+			s += 25
+		}
+		s *= 1 - linkDensity(n)
+		if s > bestScore {
+			best, bestScore = n, s
+		}
+	}
+	if best == nil {
+		return body
+	}
+	return best
+}
+
+func linkDensity(n *html.Node) float64 {
+	total := len(strings.TrimSpace(textContent(n)))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	walkNodes(n, func(c *html.Node) bool {
+		if c.DataAtom == atom.A {
+			links += len(strings.TrimSpace(textContent(c)))
+			return false
+		}
+		return true
+	})
+	return float64(links) / float64(total)
+}
+
+// mdRenderer 把 DOM 渲染为 Markdown，链接与图片地址解析为绝对 URL。
+type mdRenderer struct {
+	base      *url.URL
+	plain     bool
+	listDepth int
+}
+
+func (r *mdRenderer) render(n *html.Node) string {
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(r.node(c))
+	}
+	return sb.String()
+}
+
+func (r *mdRenderer) node(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return spaceRe.ReplaceAllString(n.Data, " ")
+	case html.ElementNode:
+	default:
+		return r.render(n)
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := strings.TrimSpace(r.render(n))
+		if text == "" {
+			return ""
+		}
+		if r.plain {
+			return "\n\n" + text + "\n\n"
+		}
+		level := int(n.Data[1] - '0')
+		return "\n\n" + strings.Repeat("#", level) + " " + text + "\n\n"
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Figure, atom.Figcaption,
+		atom.Dl, atom.Dd, atom.Dt, atom.Details, atom.Summary, atom.Address:
+		return "\n\n" + r.render(n) + "\n\n"
+	case atom.Br:
+		return "\n"
+	case atom.Hr:
+		if r.plain {
+			return "\n\n"
+		}
+		return "\n\n---\n\n"
+	case atom.A:
+		text := strings.TrimSpace(r.render(n))
+		href := r.resolve(attr(n, "href"))
+		if r.plain || href == "" || text == "" || strings.HasPrefix(href, "javascript:") {
+			return text
+		}
+		return fmt.Sprintf("[%s](%s)", text, href)
+	case atom.Img:
+		src := r.resolve(attr(n, "src"))
+		alt := strings.TrimSpace(attr(n, "alt"))
+		if r.plain || src == "" || strings.HasPrefix(src, "data:") {
+			return alt
+		}
+		return fmt.Sprintf("![%s](%s)", alt, src)
+	case atom.Strong, atom.B:
+		return r.wrapInline(n, "**")
+	case atom.Em, atom.I:
+		return r.wrapInline(n, "*")
+	case atom.Del, atom.S:
+		return r.wrapInline(n, "~~")
+	case atom.Code, atom.Kbd, atom.Samp:
+		text := textContent(n)
+		if r.plain || strings.TrimSpace(text) == "" {
+			return text
+		}
+		return "`" + text + "`"
+	case atom.Pre:
+		code := strings.Trim(textContent(n), "\n")
+		if r.plain {
+			return "\n\n" + code + "\n\n"
+		}
+		return "\n\n```" + codeLanguage(n) + "\n" + code + "\n```\n\n"
+	case atom.Ul, atom.Ol:
+		return r.list(n)
+	case atom.Blockquote:
+		inner := tidyMarkdown(r.render(n))
+		if r.plain {
+			return "\n\n" + inner + "\n\n"
+		}
+		lines := strings.Split(inner, "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimRight("> "+l, " ")
+		}
+		return "\n\n" + strings.Join(lines, "\n") + "\n\n"
+	case atom.Table:
+		return r.table(n)
+	}
+	return r.render(n)
+}
+
+func (r *mdRenderer) wrapInline(n *html.Node, mark string) string {
+	text := r.render(n)
+	trimmed := strings.TrimSpace(text)
+	if r.plain || trimmed == "" {
+		return text
+	}
+	return mark + trimmed + mark
+}
+
+func (r *mdRenderer) list(n *html.Node) string {
+	r.listDepth++
+	defer func() { r.listDepth-- }()
+	indent := strings.Repeat("  ", r.listDepth-1)
+	var items []string
+	i := 0
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.DataAtom != atom.Li {
+			continue
+		}
+		i++
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", i)
+		}
+		body := strings.TrimSpace(blankLinesRe.ReplaceAllString(collapseBlankLines(r.render(c)), "\n"))
+		items = append(items, indent+marker+body)
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	if r.listDepth > 1 {
+		return "\n" + strings.Join(items, "\n") + "\n"
+	}
+	return "\n\n" + strings.Join(items, "\n") + "\n\n"
+}
+
+func (r *mdRenderer) table(n *html.Node) string {
+	var rows [][]string
+	walkNodes(n, func(c *html.Node) bool {
+		if c.DataAtom != atom.Tr {
+			return true
+		}
+		var cells []string
+		for td := c.FirstChild; td != nil; td = td.NextSibling {
+			if td.DataAtom != atom.Td && td.DataAtom != atom.Th {
+				continue
+			}
+			cell := strings.TrimSpace(spaceRe.ReplaceAllString(r.render(td), " "))
+			cells = append(cells, strings.ReplaceAll(cell, "|", "\\|"))
+		}
+		if len(cells) > 0 {
+			rows = append(rows, cells)
+		}
+		return false
+	})
+	if len(rows) == 0 {
+		return ""
+	}
+	width := 0
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\n")
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		if r.plain {
+			sb.WriteString(strings.Join(row, "\t") + "\n")
+			continue
+		}
+		sb.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func (r *mdRenderer) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || r.base == nil {
+		return ref
+	}
+	u, err := r.base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+// codeLanguage 从 <pre> 或其内部 <code> 的 class（language-xxx / lang-xxx）推断代码语言。
+func codeLanguage(pre *html.Node) string {
+	for _, n := range []*html.Node{pre, pre.FirstChild} {
+		if n == nil || n.Type != html.ElementNode {
+			continue
+		}
+		for _, cls := range strings.Fields(attr(n, "class")) {
+			for _, prefix := range []string{"language-", "lang-"} {
+				if strings.HasPrefix(cls, prefix) {
+					return strings.TrimPrefix(cls, prefix)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// tidyMarkdown 去掉行尾空白并合并多余空行。
+func tidyMarkdown(s string) string {
+	return strings.TrimSpace(collapseBlankLines(s))
+}
+
+func collapseBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t")
+	}
+	return blankLinesRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
+
+// ── DOM 辅助函数 ──────────────────────────────────────────────────────────────
+
+// walkNodes 先序遍历 n 的后代节点；fn 返回 false 时不再进入该节点的子树。
+func walkNodes(n *html.Node, fn func(*html.Node) bool) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if fn(c) {
+			walkNodes(c, fn)
+		}
+	}
+}
+
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	var found *html.Node
+	walkNodes(n, func(c *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			found = c
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+func hasAncestor(n *html.Node, atoms ...atom.Atom) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		for _, a := range atoms {
+			if p.DataAtom == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	v, _ := attrOK(n, key)
+	return v
+}
+
+func attrOK(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(textContent(c))
+	}
+	return sb.String()
+}
@@ -0,0 +1,267 @@
+package tool
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/afumu/openlink/internal/security"
+	"github.com/afumu/openlink/internal/types"
+)
+
+// maxHTTPResponseBody 是 http_request 读取的最大响应体字节数。
+const maxHTTPResponseBody = 1 * 1024 * 1024
+
+var httpMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true,
+	"PATCH": true, "DELETE": true, "OPTIONS": true,
+}
+
+// HTTPRequestTool 向本机开发服务器或项目配置中允许的主机发送任意 HTTP 请求。
+// 与 web_fetch 相反，它只允许访问回环地址和 .openlink/config.json 中 http_allow_hosts 列出的主机。
+type HTTPRequestTool struct {
+	config *types.Config
+}
+
+func NewHTTPRequestTool(config *types.Config) *HTTPRequestTool {
+	return &HTTPRequestTool{config: config}
+}
+
+func (t *HTTPRequestTool) Name() string { return "http_request" }
+func (t *HTTPRequestTool) Description() string {
+	return "Send an HTTP request to a local dev server (loopback) or an allow-listed host"
+}
+func (t *HTTPRequestTool) Parameters() interface{} {
+	return map[string]string{
+		"url":     "string (required) - http/https URL on localhost or a host listed in http_allow_hosts",
+		"method":  "string (optional) - GET (default), POST, PUT, PATCH, DELETE, HEAD or OPTIONS",
+		"headers": "object (optional) - request headers as a JSON object or 'Key: Value' lines",
+		"body":    "string|object (optional) - request body; objects are sent as JSON",
+		"timeout": "number (optional) - timeout in seconds (default: server timeout)",
+	}
+}
+
+func (t *HTTPRequestTool) Validate(args map[string]interface{}) error {
+	rawURL, ok := args["url"].(string)
+	if !ok || rawURL == "" {
+		return errors.New("url is required")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("only http/https URLs are supported")
+	}
+	if m, _ := args["method"].(string); m != "" && !httpMethods[strings.ToUpper(m)] {
+		return fmt.Errorf("unsupported method: %s", m)
+	}
+	if _, err := stringMapArg(args, "headers"); err != nil {
+		return err
+	}
+	host, port := urlHostPort(u)
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve host: %s", host)
+	}
+	if t.allowListed(host, port) {
+		for _, ip := range ips {
+			if !security.IsAllowListable(ip) {
+				return fmt.Errorf("%w: %s resolves to %s", security.ErrBlockedAddress, host, ip)
+			}
+		}
+		return nil
+	}
+	for _, ip := range ips {
+		if !ip.IsLoopback() {
+			return fmt.Errorf("%s is not a loopback address; add it to http_allow_hosts in %s to allow it", host, types.ProjectConfigFile)
+		}
+	}
+	if port == t.config.Port {
+		return errors.New("requests to the openlink server itself are not allowed")
+	}
+	return nil
+}
+
+// allowListed 判断 host:port 是否在项目配置的 http_allow_hosts 中。
+func (t *HTTPRequestTool) allowListed(host string, port int) bool {
+	for _, entry := range t.config.Project.HTTPAllowHosts {
+		h, p, err := net.SplitHostPort(entry)
+		if err != nil {
+			h, p = entry, ""
+		}
+		if !strings.EqualFold(h, host) {
+			continue
+		}
+		if p == "" || p == strconv.Itoa(port) {
+			return true
+		}
+	}
+	return false
+}
+
+// dialPolicy 返回访问 host:port 时的拨号校验：只允许该端口；放行的主机仍不能
+// 连接链路本地、元数据等地址，其余主机只能连接回环地址（openlink 自身端口除外）。
+func (t *HTTPRequestTool) dialPolicy(host string, port int) security.IPPolicy {
+	allowed := t.allowListed(host, port)
+	return func(ip net.IP, p int) bool {
+		if p != port {
+			return false
+		}
+		if allowed {
+			return security.IsAllowListable(ip)
+		}
+		return ip.IsLoopback() && p != t.config.Port
+	}
+}
+
+// urlHostPort 返回 URL 的主机名和端口，未写端口时按协议取默认值。
+func urlHostPort(u *url.URL) (string, int) {
+	port := 80
+	if u.Scheme == "https" {
+		port = 443
+	}
+	if p, err := strconv.Atoi(u.Port()); err == nil {
+		port = p
+	}
+	return u.Hostname(), port
+}
+
+func (t *HTTPRequestTool) Execute(ctx *Context) *Result {
+	result := &Result{StartTime: time.Now()}
+	rawURL, _ := ctx.Args["url"].(string)
+	method, _ := ctx.Args["method"].(string)
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		method = http.MethodGet
+	}
+	headers, err := stringMapArg(ctx.Args, "headers")
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+
+	var body io.Reader
+	switch b := ctx.Args["body"].(type) {
+	case nil:
+	case string:
+		body = strings.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			result.Status = "error"
+			result.Error = err.Error()
+			return result
+		}
+		body = bytes.NewReader(data)
+		if !hasHeader(headers, "Content-Type") {
+			headers["Content-Type"] = "application/json"
+		}
+	}
+
+	req, err := http.NewRequest(method, rawURL, body)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	timeout := time.Duration(t.config.Timeout) * time.Second
+	if v, ok := intArg(ctx.Args, "timeout"); ok && v > 0 {
+		timeout = time.Duration(v) * time.Second
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	// 拨号时再校验一次实际连接的地址，防止 DNS 结果在 Validate 之后变化
+	host, port := urlHostPort(req.URL)
+	client := security.NewGuardedClient(timeout, t.dialPolicy(host, port))
+	// 不跟随重定向：开发接口的 3xx 本身就是需要观察的结果
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseBody+1))
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	output, _ := Truncate(formatHTTPResponse(resp, data, time.Since(start)))
+	result.Status = "success"
+	result.Output = output
+	result.EndTime = time.Now()
+	return result
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatHTTPResponse 将响应格式化为状态行、按名称排序的响应头和响应体三部分。
+// JSON 响应体会被格式化缩进，二进制响应体只给出类型与大小。
+func formatHTTPResponse(resp *http.Response, data []byte, elapsed time.Duration) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s (%dms)\n", resp.Proto, resp.Status, elapsed.Milliseconds())
+
+	names := make([]string, 0, len(resp.Header))
+	for k := range resp.Header {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		for _, v := range resp.Header[k] {
+			fmt.Fprintf(&b, "%s: %s\n", k, v)
+		}
+	}
+	b.WriteString("\n")
+
+	truncated := len(data) > maxHTTPResponseBody
+	if truncated {
+		data = data[:maxHTTPResponseBody]
+	}
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case len(data) == 0:
+		b.WriteString("(empty body)")
+	case !utf8.Valid(data) && !truncated:
+		fmt.Fprintf(&b, "(binary body, %s, %s)", formatSize(int64(len(data))), contentType)
+	default:
+		var pretty bytes.Buffer
+		if (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) && json.Indent(&pretty, data, "", "  ") == nil {
+			b.Write(pretty.Bytes())
+		} else {
+			b.Write(data)
+		}
+	}
+	if truncated {
+		fmt.Fprintf(&b, "\n(响应体超过 %s，已截断)", formatSize(maxHTTPResponseBody))
+	}
+	return b.String()
+}
@@ -0,0 +1,158 @@
+package tool
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/afumu/openlink/internal/security"
+	"github.com/afumu/openlink/internal/types"
+)
+
+func TestHTTPRequestValidate(t *testing.T) {
+	cfg := &types.Config{RootDir: t.TempDir(), Port: 39527}
+	cfg.Project.HTTPAllowHosts = []string{"10.0.0.5:8080", "93.184.216.34"}
+	tool := NewHTTPRequestTool(cfg)
+
+	ok := []string{
+		"http://127.0.0.1:8080/api",
+		"http://localhost:3000/",
+		"http://10.0.0.5:8080/health",
+		"https://93.184.216.34/",
+	}
+	for _, u := range ok {
+		if err := tool.Validate(map[string]interface{}{"url": u}); err != nil {
+			t.Errorf("%s: unexpected error: %v", u, err)
+		}
+	}
+
+	bad := []map[string]interface{}{
+		{},
+		{"url": "ftp://127.0.0.1/"},
+		{"url": "http://8.8.8.8/"},
+		{"url": "http://10.0.0.5:9090/"},
+		{"url": "http://127.0.0.1:39527/exec"},
+		{"url": "http://127.0.0.1:8080/", "method": "TRACE"},
+		{"url": "http://127.0.0.1:8080/", "headers": "not a header"},
+	}
+	for _, args := range bad {
+		if err := tool.Validate(args); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+}
+
+func TestHTTPRequestAllowListKeepsMetadataBlocked(t *testing.T) {
+	cfg := &types.Config{RootDir: t.TempDir(), Port: 39527}
+	cfg.Project.HTTPAllowHosts = []string{"metadata.internal", "169.254.169.254", "api.dev.test"}
+	tool := NewHTTPRequestTool(cfg)
+
+	if err := tool.Validate(map[string]interface{}{"url": "http://169.254.169.254/latest/meta-data/"}); !errors.Is(err, security.ErrBlockedAddress) {
+		t.Errorf("expected allow-listed metadata address to be refused, got %v", err)
+	}
+
+	// 放行的名称在拨号时解析到元数据或链路本地地址仍被拒绝，私有地址则可以访问
+	policy := tool.dialPolicy("metadata.internal", 80)
+	for _, ip := range []string{"169.254.169.254", "fe80::1", "fd00:ec2::254", "0.0.0.0", "::ffff:169.254.169.254"} {
+		if policy(net.ParseIP(ip), 80) {
+			t.Errorf("%s should stay blocked for an allow-listed host", ip)
+		}
+	}
+	if !policy(net.ParseIP("10.0.0.5"), 80) {
+		t.Error("private address should be reachable for an allow-listed host")
+	}
+	if tool.dialPolicy("other.test", 80)(net.ParseIP("10.0.0.5"), 80) {
+		t.Error("hosts that are not allow-listed may only reach loopback")
+	}
+}
+
+func TestProjectConfigNotWritable(t *testing.T) {
+	cfg := testConfig(t)
+	os.MkdirAll(filepath.Join(cfg.RootDir, ".openlink"), 0755)
+	os.WriteFile(filepath.Join(cfg.RootDir, types.ProjectConfigFile), []byte("{}"), 0644)
+
+	res := NewWriteFileTool(cfg).Execute(testCtx(cfg, map[string]interface{}{
+		"path": types.ProjectConfigFile, "content": `{"http_allow_hosts":["metadata.internal"]}`,
+	}))
+	if res.Status != "error" || !strings.Contains(res.Error, "only be edited by the user") {
+		t.Errorf("write_file should refuse the project config, got %s %q", res.Status, res.Error)
+	}
+	res = NewWriteFileTool(cfg).Execute(testCtx(cfg, map[string]interface{}{
+		"path": filepath.Join(cfg.RootDir, ".openlink", "..", ".openlink", "config.json"), "content": "{}",
+	}))
+	if res.Status != "error" {
+		t.Error("write_file should refuse the project config by absolute path")
+	}
+	if data, _ := os.ReadFile(filepath.Join(cfg.RootDir, types.ProjectConfigFile)); string(data) != "{}" {
+		t.Errorf("project config was modified: %s", data)
+	}
+	if err := NewWebFetchTool(cfg).Validate(map[string]interface{}{"url": "https://example.com/", "save_to": types.ProjectConfigFile}); err == nil {
+		t.Error("web_fetch save_to should refuse the project config")
+	}
+}
+
+func TestHTTPRequestExecute(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Method", r.Method)
+			w.Header().Set("X-Token", r.Header.Get("X-Token"))
+			w.Write(body)
+		case "/old":
+			http.Redirect(w, r, "http://8.8.8.8/", http.StatusMovedPermanently)
+		case "/bin":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G', 0xff, 0xfe, 0x00})
+		}
+	}))
+	defer srv.Close()
+
+	cfg := &types.Config{RootDir: t.TempDir(), Timeout: 10}
+	tool := NewHTTPRequestTool(cfg)
+	run := func(args map[string]interface{}) *Result {
+		t.Helper()
+		if err := tool.Validate(args); err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+		return tool.Execute(&Context{Args: args, Config: cfg})
+	}
+
+	t.Run("json body pretty printed", func(t *testing.T) {
+		res := run(map[string]interface{}{
+			"url":     srv.URL + "/echo",
+			"method":  "post",
+			"headers": "X-Token: abc",
+			"body":    map[string]interface{}{"name": "alice"},
+		})
+		if res.Status != "success" {
+			t.Fatal(res.Error)
+		}
+		for _, want := range []string{"200 OK", "X-Method: POST", "X-Token: abc", "Content-Type: application/json", "{\n  \"name\": \"alice\"\n}"} {
+			if !strings.Contains(res.Output, want) {
+				t.Errorf("missing %q in:\n%s", want, res.Output)
+			}
+		}
+	})
+
+	t.Run("redirects not followed", func(t *testing.T) {
+		res := run(map[string]interface{}{"url": srv.URL + "/old"})
+		if res.Status != "success" || !strings.Contains(res.Output, "301") || !strings.Contains(res.Output, "Location: http://8.8.8.8/") {
+			t.Errorf("expected raw 301 response, got %s %q", res.Error, res.Output)
+		}
+	})
+
+	t.Run("binary body summarized", func(t *testing.T) {
+		res := run(map[string]interface{}{"url": srv.URL + "/bin"})
+		if !strings.Contains(res.Output, "(binary body, 7 B, image/png)") {
+			t.Errorf("unexpected output: %q", res.Output)
+		}
+	})
+}
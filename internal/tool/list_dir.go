@@ -2,6 +2,7 @@ package tool
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,6 +10,13 @@ import (
 
 	"github.com/afumu/openlink/internal/security"
 	"github.com/afumu/openlink/internal/types"
+	"github.com/afumu/openlink/internal/walk"
+)
+
+const (
+	defaultListMaxEntries = 500
+	// listCollapseFiles 以上文件数的目录不逐个列出文件，只显示数量。
+	listCollapseFiles = 200
 )
 
 type ListDirTool struct {
@@ -24,12 +32,17 @@ func (t *ListDirTool) Name() string {
 }
 
 func (t *ListDirTool) Description() string {
-	return "List directory contents"
+	return "List directory contents as an indented tree"
 }
 
 func (t *ListDirTool) Parameters() interface{} {
 	return map[string]string{
-		"path": "string (required) - directory path to list",
+		"path":            "string (required) - directory path to list",
+		"depth":           "number (optional) - how many levels to expand (default 1)",
+		"show_sizes":      "bool (optional) - show file sizes",
+		"show_mtime":      "bool (optional) - show modification times",
+		"max_entries":     "number (optional) - max lines to output (default 500)",
+		"include_ignored": "bool (optional) - expand vendor, VCS and .gitignore'd directories instead of skipping them",
 	}
 }
 
@@ -41,6 +54,17 @@ func (t *ListDirTool) Validate(args map[string]interface{}) error {
 	return nil
 }
 
+// treeLister 保存一次 list_dir 调用的渲染状态。
+type treeLister struct {
+	depth          int
+	showSizes      bool
+	showMtime      bool
+	maxEntries     int
+	includeIgnored bool
+	lines          []string
+	truncated      bool
+}
+
 func (t *ListDirTool) Execute(ctx *Context) *Result {
 	result := &Result{StartTime: time.Now()}
 	path, _ := ctx.Args["path"].(string)
@@ -58,27 +82,171 @@ func (t *ListDirTool) Execute(ctx *Context) *Result {
 		return result
 	}
 
+	l := &treeLister{
+		depth:          1,
+		showSizes:      boolArg(ctx.Args, "show_sizes"),
+		showMtime:      boolArg(ctx.Args, "show_mtime"),
+		maxEntries:     defaultListMaxEntries,
+		includeIgnored: boolArg(ctx.Args, "include_ignored"),
+	}
+	if v, ok := intArg(ctx.Args, "depth"); ok && v >= 1 {
+		l.depth = v
+	}
+	if v, ok := intArg(ctx.Args, "max_entries"); ok && v >= 1 {
+		l.maxEntries = v
+	}
+
 	entries, err := os.ReadDir(safePath)
 	if err != nil {
 		result.Status = "error"
 		result.Error = err.Error()
 		return result
 	}
+	l.render(safePath, entries, 0, loadGitIgnore(safePath))
 
-	var names []string
-	for _, e := range entries {
-		name := e.Name()
-		if e.IsDir() {
-			name += "/"
-		}
-		names = append(names, name)
+	if l.truncated {
+		l.lines = append(l.lines, fmt.Sprintf("(输出已截断，达到 max_entries=%d 上限，可缩小 depth 或指定子目录)", l.maxEntries))
 	}
-
 	result.Status = "success"
-	result.Output = strings.Join(names, "\n")
+	result.Output = strings.Join(l.lines, "\n")
 	if result.Output == "" {
 		result.Output = "empty"
 	}
 	result.EndTime = time.Now()
 	return result
 }
+
+// render 输出 dir 下的条目：目录在前并附带文件/子目录数量，超出 depth 的目录不再展开。
+func (l *treeLister) render(dir string, entries []os.DirEntry, level int, ign *gitIgnore) {
+	indent := strings.Repeat("  ", level)
+	var dirs, files []os.DirEntry
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, e)
+		} else {
+			files = append(files, e)
+		}
+	}
+
+	for _, d := range dirs {
+		if !l.emit() {
+			return
+		}
+		p := filepath.Join(dir, d.Name())
+		if !l.includeIgnored && (walk.SkipDir(d.Name()) || ign.Ignored(p, true)) {
+			l.lines = append(l.lines, fmt.Sprintf("%s%s/ (skipped)", indent, d.Name()))
+			continue
+		}
+		children, err := os.ReadDir(p)
+		if err != nil {
+			l.lines = append(l.lines, fmt.Sprintf("%s%s/ (%v)", indent, d.Name(), err))
+			continue
+		}
+		l.lines = append(l.lines, fmt.Sprintf("%s%s/ %s", indent, d.Name(), countSummary(children)))
+		if level+1 < l.depth {
+			l.render(p, children, level+1, ign.withDir(p))
+			if l.truncated {
+				return
+			}
+		}
+	}
+
+	if len(files) > listCollapseFiles {
+		if l.emit() {
+			l.lines = append(l.lines, fmt.Sprintf("%s… %s files", indent, formatCount(len(files))))
+		}
+		return
+	}
+	for _, f := range files {
+		if !l.emit() {
+			return
+		}
+		l.lines = append(l.lines, indent+l.fileLine(f))
+	}
+}
+
+// emit 检查是否还能输出一行，超出 maxEntries 时标记截断。
+func (l *treeLister) emit() bool {
+	if len(l.lines) >= l.maxEntries {
+		l.truncated = true
+		return false
+	}
+	return true
+}
+
+func (l *treeLister) fileLine(f os.DirEntry) string {
+	name := f.Name()
+	if !l.showSizes && !l.showMtime {
+		return name
+	}
+	info, err := f.Info()
+	if err != nil {
+		return name
+	}
+	parts := []string{name}
+	if l.showSizes {
+		parts = append(parts, formatSize(info.Size()))
+	}
+	if l.showMtime {
+		parts = append(parts, info.ModTime().Format("2006-01-02 15:04"))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// countSummary 返回目录直接包含的文件与子目录数量，如 "(12 files, 3 dirs)"。
+func countSummary(entries []os.DirEntry) string {
+	nDirs := 0
+	for _, e := range entries {
+		if e.IsDir() {
+			nDirs++
+		}
+	}
+	nFiles := len(entries) - nDirs
+	parts := []string{fmt.Sprintf("%s %s", formatCount(nFiles), plural(nFiles, "file"))}
+	if nDirs > 0 {
+		parts = append(parts, fmt.Sprintf("%s %s", formatCount(nDirs), plural(nDirs, "dir")))
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// formatCount 以千位分隔符格式化整数，如 3214 → "3,214"。
+func formatCount(n int) string {
+	s := fmt.Sprint(n)
+	if n < 1000 {
+		return s
+	}
+	var b strings.Builder
+	pre := len(s) % 3
+	if pre > 0 {
+		b.WriteString(s[:pre])
+	}
+	for i := pre; i < len(s); i += 3 {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(s[i : i+3])
+	}
+	return b.String()
+}
+
+// formatSize 以人类可读形式格式化字节数。
+func formatSize(n int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	v := float64(n)
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
@@ -6,6 +6,11 @@ import (
 	"time"
 )
 
+const (
+	defaultQuestionTimeout = 5 * time.Minute
+	maxQuestionTimeout     = 30 * time.Minute
+)
+
 type QuestionTool struct{}
 
 func NewQuestionTool() *QuestionTool { return &QuestionTool{} }
@@ -14,8 +19,11 @@ func (t *QuestionTool) Name() string        { return "question" }
 func (t *QuestionTool) Description() string { return "Ask the user a question and wait for input" }
 func (t *QuestionTool) Parameters() interface{} {
 	return map[string]string{
-		"question": "string (required) - the question to ask",
-		"options":  "array (optional) - list of choices to present",
+		"question":   "string (required) - the question to ask",
+		"options":    "array (optional) - list of choices to present",
+		"multiple":   "bool (optional) - allow choosing several options",
+		"allow_text": "bool (optional) - allow a free-text answer (default true without options, false with options)",
+		"timeout":    "number (optional) - seconds to wait for the answer (default 300, max 1800)",
 	}
 }
 
@@ -23,14 +31,63 @@ func (t *QuestionTool) Validate(args map[string]interface{}) error {
 	if q, ok := args["question"].(string); !ok || q == "" {
 		return fmt.Errorf("question is required")
 	}
+	options := stringListArg(args, "options")
+	if boolArg(args, "multiple") && len(options) == 0 {
+		return fmt.Errorf("multiple requires options")
+	}
+	if _, ok := args["allow_text"]; ok && !boolArg(args, "allow_text") && len(options) == 0 {
+		return fmt.Errorf("a question without options must allow a text answer")
+	}
 	return nil
 }
 
 func (t *QuestionTool) Execute(ctx *Context) *Result {
 	result := &Result{StartTime: time.Now()}
 	question, _ := ctx.Args["question"].(string)
-	options, _ := ctx.Args["options"].([]interface{})
+	options := stringListArg(ctx.Args, "options")
+
+	// 没有问题登记中心（如单独调用工具）时，退化为把问题作为输出返回
+	if ctx.Questions == nil {
+		result.Status = "success"
+		result.Output = formatQuestion(question, options)
+		result.EndTime = time.Now()
+		return result
+	}
 
+	allowText := len(options) == 0
+	if _, ok := ctx.Args["allow_text"]; ok {
+		allowText = boolArg(ctx.Args, "allow_text")
+	}
+	timeout := defaultQuestionTimeout
+	if v, ok := intArg(ctx.Args, "timeout"); ok && v > 0 {
+		timeout = min(time.Duration(v)*time.Second, maxQuestionTimeout)
+	}
+
+	answer, err := ctx.Questions.Ask(Question{
+		Session:   ctx.Session,
+		Question:  question,
+		Options:   options,
+		Multiple:  boolArg(ctx.Args, "multiple"),
+		AllowText: allowText,
+	}, timeout, ctx.Done)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	if answer.Cancel {
+		result.Status = "error"
+		result.Error = "用户取消了提问，请不要自行假设答案"
+		return result
+	}
+
+	result.Status = "success"
+	result.Output = answer.Format()
+	result.EndTime = time.Now()
+	return result
+}
+
+func formatQuestion(question string, options []string) string {
 	var sb strings.Builder
 	sb.WriteString("[需要您的输入]\n\n")
 	sb.WriteString(question)
@@ -42,9 +99,5 @@ func (t *QuestionTool) Execute(ctx *Context) *Result {
 		}
 		sb.WriteString("\n\n请输入您的选择或回答：")
 	}
-
-	result.Status = "success"
-	result.Output = sb.String()
-	result.EndTime = time.Now()
-	return result
+	return sb.String()
 }
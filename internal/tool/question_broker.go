@@ -0,0 +1,178 @@
+package tool
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrQuestionNotFound 表示问题不存在或已被回答、取消、超时。
+var ErrQuestionNotFound = errors.New("question not found or already answered")
+
+// Question 是一个等待用户回答的问题。
+type Question struct {
+	ID        string    `json:"id"`
+	Session   string    `json:"session,omitempty"`
+	Question  string    `json:"question"`
+	Options   []string  `json:"options,omitempty"`
+	Multiple  bool      `json:"multiple"`
+	AllowText bool      `json:"allow_text"`
+	CreatedAt time.Time `json:"created_at"`
+	Deadline  time.Time `json:"deadline"`
+
+	answer chan Answer
+}
+
+// Answer 是用户对问题的回答：选中的选项、自由文本，或取消。
+type Answer struct {
+	Choices []string `json:"choices,omitempty"`
+	Text    string   `json:"text,omitempty"`
+	Cancel  bool     `json:"cancel,omitempty"`
+}
+
+// QuestionBroker 登记 question 工具发起的问题，并把扩展或终端给出的回答交回给阻塞中的工具调用。
+type QuestionBroker struct {
+	mu      sync.Mutex
+	pending map[string]*Question
+	seq     int
+	// OnAsk 在登记新问题后调用（如在终端打印问题），可为 nil
+	OnAsk func(q Question)
+}
+
+func NewQuestionBroker() *QuestionBroker {
+	return &QuestionBroker{pending: make(map[string]*Question)}
+}
+
+// Ask 登记问题并阻塞，直到收到回答、超时或 done 被关闭。
+func (b *QuestionBroker) Ask(q Question, timeout time.Duration, done <-chan struct{}) (Answer, error) {
+	b.mu.Lock()
+	b.seq++
+	q.ID = "q" + strconv.Itoa(b.seq)
+	q.CreatedAt = time.Now()
+	q.Deadline = q.CreatedAt.Add(timeout)
+	q.answer = make(chan Answer, 1)
+	b.pending[q.ID] = &q
+	onAsk := b.OnAsk
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.pending, q.ID)
+		b.mu.Unlock()
+	}()
+	if onAsk != nil {
+		onAsk(q)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case a := <-q.answer:
+		return a, nil
+	case <-timer.C:
+		return Answer{}, fmt.Errorf("等待用户回答超时（%s）", timeout)
+	case <-done:
+		return Answer{}, errors.New("提问已取消：请求已断开")
+	}
+}
+
+// Pending 返回等待回答的问题，按创建时间排序；session 非空时只返回该会话的问题。
+func (b *QuestionBroker) Pending(session string) []Question {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]Question, 0, len(b.pending))
+	for _, q := range b.pending {
+		if session == "" || q.Session == session {
+			out = append(out, *q)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// Answer 校验并提交对问题 id 的回答。
+func (b *QuestionBroker) Answer(id string, a Answer) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	q, ok := b.pending[id]
+	if !ok {
+		return ErrQuestionNotFound
+	}
+	if err := q.check(a); err != nil {
+		return err
+	}
+	delete(b.pending, id)
+	q.answer <- a
+	return nil
+}
+
+// check 校验回答是否符合问题的设置。
+func (q *Question) check(a Answer) error {
+	if a.Cancel {
+		return nil
+	}
+	a.Text = strings.TrimSpace(a.Text)
+	if len(a.Choices) == 0 && a.Text == "" {
+		return errors.New("answer is empty")
+	}
+	if len(a.Choices) > 1 && !q.Multiple {
+		return errors.New("only one option may be chosen")
+	}
+	for _, c := range a.Choices {
+		if !containsString(q.Options, c) {
+			return fmt.Errorf("unknown option %q", c)
+		}
+	}
+	if a.Text != "" && !q.AllowText {
+		return errors.New("free-text answers are not allowed for this question")
+	}
+	return nil
+}
+
+// ParseReply 将终端输入解析为回答：编号（多选时以逗号或空格分隔）对应选项，其余作为自由文本。
+func (q *Question) ParseReply(line string) (Answer, error) {
+	line = strings.TrimSpace(line)
+	if len(q.Options) > 0 {
+		fields := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == '，' || r == ' ' })
+		var choices []string
+		for _, f := range fields {
+			n, err := strconv.Atoi(f)
+			if err != nil || n < 1 || n > len(q.Options) {
+				choices = nil
+				break
+			}
+			choices = append(choices, q.Options[n-1])
+		}
+		if len(choices) > 0 {
+			a := Answer{Choices: choices}
+			return a, q.check(a)
+		}
+	}
+	a := Answer{Text: line}
+	return a, q.check(a)
+}
+
+// Format 将回答格式化为返回给模型的文本。
+func (a Answer) Format() string {
+	var parts []string
+	if len(a.Choices) > 0 {
+		parts = append(parts, "用户选择："+strings.Join(a.Choices, "、"))
+	}
+	if t := strings.TrimSpace(a.Text); t != "" {
+		parts = append(parts, "用户回答："+t)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
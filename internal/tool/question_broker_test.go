@@ -0,0 +1,84 @@
+package tool
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuestionBrokerAnswer(t *testing.T) {
+	b := NewQuestionBroker()
+	asked := make(chan Question, 1)
+	b.OnAsk = func(q Question) { asked <- q }
+
+	type reply struct {
+		a   Answer
+		err error
+	}
+	done := make(chan reply, 1)
+	go func() {
+		a, err := b.Ask(Question{Session: "s1", Question: "pick", Options: []string{"A", "B", "C"}, Multiple: true}, time.Minute, nil)
+		done <- reply{a, err}
+	}()
+	q := <-asked
+
+	if got := b.Pending("s2"); len(got) != 0 {
+		t.Errorf("other session sees %d questions", len(got))
+	}
+	if got := b.Pending("s1"); len(got) != 1 || got[0].ID != q.ID {
+		t.Fatalf("pending = %+v", got)
+	}
+	if err := b.Answer(q.ID, Answer{Choices: []string{"D"}}); err == nil {
+		t.Error("unknown option accepted")
+	}
+	if err := b.Answer(q.ID, Answer{Text: "free"}); err == nil {
+		t.Error("text accepted although allow_text is false")
+	}
+	if err := b.Answer(q.ID, Answer{Choices: []string{"A", "C"}}); err != nil {
+		t.Fatal(err)
+	}
+	r := <-done
+	if r.err != nil || r.a.Format() != "用户选择：A、C" {
+		t.Errorf("got %+v, %v", r.a, r.err)
+	}
+	if err := b.Answer(q.ID, Answer{Cancel: true}); !errors.Is(err, ErrQuestionNotFound) {
+		t.Errorf("second answer: %v", err)
+	}
+	if len(b.Pending("")) != 0 {
+		t.Error("answered question still pending")
+	}
+}
+
+func TestQuestionBrokerTimeoutAndDone(t *testing.T) {
+	b := NewQuestionBroker()
+	if _, err := b.Ask(Question{Question: "q", AllowText: true}, 10*time.Millisecond, nil); err == nil || !strings.Contains(err.Error(), "超时") {
+		t.Errorf("timeout: %v", err)
+	}
+
+	done := make(chan struct{})
+	close(done)
+	if _, err := b.Ask(Question{Question: "q", AllowText: true}, time.Minute, done); err == nil || !strings.Contains(err.Error(), "断开") {
+		t.Errorf("done: %v", err)
+	}
+	if len(b.Pending("")) != 0 {
+		t.Error("abandoned questions still pending")
+	}
+}
+
+func TestQuestionParseReply(t *testing.T) {
+	q := &Question{Options: []string{"A", "B", "C"}, Multiple: true, AllowText: true}
+	if a, err := q.ParseReply("1, 3"); err != nil || strings.Join(a.Choices, ",") != "A,C" {
+		t.Errorf("numbers: %+v %v", a, err)
+	}
+	if a, err := q.ParseReply("something else"); err != nil || a.Text != "something else" {
+		t.Errorf("text: %+v %v", a, err)
+	}
+	single := &Question{Options: []string{"A", "B"}}
+	if _, err := single.ParseReply("1 2"); err == nil {
+		t.Error("multiple choices accepted for single-choice question")
+	}
+	if _, err := single.ParseReply("9"); err == nil {
+		t.Error("out-of-range number accepted as text")
+	}
+}
@@ -2,8 +2,11 @@ package tool
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -81,13 +84,20 @@ func (t *ReadFileTool) Execute(ctx *Context) *Result {
 		return result
 	}
 	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
 
 	var lines []string
 	totalLines := 0
 	byteCount := 0
 	truncated := false
 
-	scanner := bufio.NewScanner(f)
+	hasher := sha256.New()
+	scanner := bufio.NewScanner(io.TeeReader(f, hasher))
 	for scanner.Scan() {
 		totalLines++
 		if totalLines < offset {
@@ -119,6 +129,9 @@ func (t *ReadFileTool) Execute(ctx *Context) *Result {
 		return result
 	}
 
+	hash := hex.EncodeToString(hasher.Sum(nil))[:16]
+	ctx.Files.Record(ctx.Session, safePath, hash, info.ModTime())
+
 	output := strings.Join(lines, "\n")
 	if output == "" {
 		output = "empty"
@@ -127,6 +140,7 @@ func (t *ReadFileTool) Execute(ctx *Context) *Result {
 		nextOffset := offset + len(lines)
 		output += fmt.Sprintf("\n[truncated, %d total lines, use offset=%d to continue]", totalLines, nextOffset)
 	}
+	output += fmt.Sprintf("\n[hash: %s]", hash)
 
 	result.Status = "success"
 	result.Output = output
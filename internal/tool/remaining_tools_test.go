@@ -1,10 +1,12 @@
 package tool
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/afumu/openlink/internal/types"
 )
@@ -42,6 +44,50 @@ func TestListDirTool(t *testing.T) {
 	})
 }
 
+func TestListDirTree(t *testing.T) {
+	cfg := &types.Config{RootDir: t.TempDir(), Timeout: 10}
+	write := func(rel string, size int) {
+		p := filepath.Join(cfg.RootDir, rel)
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, make([]byte, size), 0644)
+	}
+	write("src/api/handler.go", 2048)
+	write("src/main.go", 10)
+	write("node_modules/pkg/index.js", 1)
+	write("README.md", 1)
+	for i := 0; i < 300; i++ {
+		write(filepath.Join("data", fmt.Sprintf("f%d.csv", i)), 1)
+	}
+	tool := NewListDirTool(cfg)
+
+	t.Run("renders nested tree with counts", func(t *testing.T) {
+		res := tool.Execute(testCtx(cfg, map[string]interface{}{"path": ".", "depth": float64(3), "show_sizes": true}))
+		for _, want := range []string{
+			"src/ (1 file, 1 dir)",
+			"  api/ (1 file)",
+			"    handler.go  2.0 KB",
+			"node_modules/ (skipped)",
+			"data/ (300 files)",
+			"  … 300 files",
+		} {
+			if !strings.Contains(res.Output, want) {
+				t.Errorf("expected %q in output:\n%s", want, res.Output)
+			}
+		}
+		if strings.Contains(res.Output, "index.js") {
+			t.Error("vendor dir should not be expanded")
+		}
+	})
+
+	t.Run("max_entries truncates", func(t *testing.T) {
+		res := tool.Execute(testCtx(cfg, map[string]interface{}{"path": ".", "depth": "3", "max_entries": "2"}))
+		lines := strings.Split(res.Output, "\n")
+		if len(lines) != 3 || !strings.Contains(lines[2], "max_entries=2") {
+			t.Errorf("got %q", res.Output)
+		}
+	})
+}
+
 func TestQuestionTool(t *testing.T) {
 	tool := NewQuestionTool()
 
@@ -67,6 +113,38 @@ func TestQuestionTool(t *testing.T) {
 			t.Error("expected error")
 		}
 	})
+
+	t.Run("validate rejects multiple without options", func(t *testing.T) {
+		if err := tool.Validate(map[string]interface{}{"question": "q", "multiple": "true"}); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("blocks until answered through broker", func(t *testing.T) {
+		broker := NewQuestionBroker()
+		broker.OnAsk = func(q Question) {
+			go broker.Answer(q.ID, Answer{Choices: []string{"B"}})
+		}
+		res := tool.Execute(&Context{
+			Args:      map[string]interface{}{"question": "Pick one", "options": `["A","B"]`},
+			Session:   "s1",
+			Questions: broker,
+		})
+		if res.Status != "success" || res.Output != "用户选择：B" {
+			t.Errorf("got %+v", res)
+		}
+	})
+
+	t.Run("cancel is reported as error", func(t *testing.T) {
+		broker := NewQuestionBroker()
+		broker.OnAsk = func(q Question) {
+			go broker.Answer(q.ID, Answer{Cancel: true})
+		}
+		res := tool.Execute(&Context{Args: map[string]interface{}{"question": "Name?"}, Questions: broker})
+		if res.Status != "error" || !strings.Contains(res.Error, "取消") {
+			t.Errorf("got %+v", res)
+		}
+	})
 }
 
 func TestInvalidTool(t *testing.T) {
@@ -79,16 +157,36 @@ func TestInvalidTool(t *testing.T) {
 
 func TestTodoWriteTool(t *testing.T) {
 	cfg := &types.Config{RootDir: t.TempDir(), Timeout: 10}
-	tool := NewTodoWriteTool(cfg)
+	store := NewTodoStore(cfg.RootDir)
+	tool := NewTodoWriteTool(cfg, store)
 
-	t.Run("writes todos to file", func(t *testing.T) {
-		todos := []interface{}{"task1", "task2"}
-		res := tool.Execute(testCtx(cfg, map[string]interface{}{"todos": todos}))
+	t.Run("writes todos for the session under .openlink", func(t *testing.T) {
+		todos := `[{"content":"task1","status":"in_progress","priority":"high"},"task2"]`
+		ctx := testCtx(cfg, map[string]interface{}{"todos": todos})
+		ctx.Session = "s1"
+		res := tool.Execute(ctx)
 		if res.Status != "success" {
 			t.Fatalf("expected success: %s", res.Error)
 		}
-		if _, err := os.Stat(filepath.Join(cfg.RootDir, ".todos.json")); err != nil {
-			t.Error("expected .todos.json to exist")
+		if !strings.Contains(res.Output, "- [~] 1. task1（high）") || !strings.Contains(res.Output, "- [ ] 2. task2") {
+			t.Errorf("got %q", res.Output)
+		}
+		if _, err := os.Stat(filepath.Join(cfg.RootDir, ".todos.json")); err == nil {
+			t.Error("todos must not be written to the repo root")
+		}
+		entries, _ := os.ReadDir(filepath.Join(cfg.RootDir, TodosDir))
+		if len(entries) != 1 {
+			t.Errorf("expected one todo file, got %d", len(entries))
+		}
+
+		read := NewTodoReadTool(cfg, store).Execute(ctx)
+		if read.Status != "success" || !strings.Contains(read.Output, "已完成 0/2") {
+			t.Errorf("todo_read got %q", read.Output)
+		}
+		other := testCtx(cfg, map[string]interface{}{})
+		other.Session = "s2"
+		if read := NewTodoReadTool(cfg, store).Execute(other); read.Output != "当前没有待办事项" {
+			t.Errorf("other session got %q", read.Output)
 		}
 	})
 
@@ -97,6 +195,51 @@ func TestTodoWriteTool(t *testing.T) {
 			t.Error("expected error")
 		}
 	})
+
+	t.Run("validate rejects invalid todos", func(t *testing.T) {
+		for _, todos := range []string{
+			`{"content":"x"}`,
+			`[{"status":"pending"}]`,
+			`[{"content":"a","status":"blocked"}]`,
+			`[{"content":"a","priority":"urgent"}]`,
+			`[{"id":"1","content":"a"},{"id":"1","content":"b"}]`,
+			`[{"content":"a","status":"in_progress"},{"content":"b","status":"in_progress"}]`,
+		} {
+			if err := tool.Validate(map[string]interface{}{"todos": todos}); err == nil {
+				t.Errorf("expected error for %s", todos)
+			}
+		}
+	})
+}
+
+func TestTodoStoreTimestamps(t *testing.T) {
+	store := NewTodoStore(t.TempDir())
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	todos, _ := parseTodos(`[{"id":"a","content":"one"},{"id":"b","content":"two"}]`)
+	if _, err := store.Replace("s", todos); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Hour)
+	todos, _ = parseTodos(`[{"id":"a","content":"one","status":"done"},{"id":"b","content":"two"},{"id":"c","content":"three"}]`)
+	got, err := store.Replace("s", todos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := now.Add(-time.Hour)
+	if !got[0].CreatedAt.Equal(start) || !got[0].UpdatedAt.Equal(now) {
+		t.Errorf("changed item: %+v", got[0])
+	}
+	if !got[1].CreatedAt.Equal(start) || !got[1].UpdatedAt.Equal(start) {
+		t.Errorf("unchanged item: %+v", got[1])
+	}
+	if !got[2].CreatedAt.Equal(now) {
+		t.Errorf("new item: %+v", got[2])
+	}
+	if !HasOpenTodos(got) {
+		t.Error("expected open todos")
+	}
 }
 
 func TestSkillTool(t *testing.T) {
@@ -128,4 +271,25 @@ func TestSkillTool(t *testing.T) {
 			t.Errorf("got status=%s output=%q", res.Status, res.Output)
 		}
 	})
+
+	t.Run("substitutes arguments", func(t *testing.T) {
+		sub := filepath.Join(cfg.RootDir, ".skills", "greet")
+		os.MkdirAll(sub, 0755)
+		os.WriteFile(filepath.Join(sub, "SKILL.md"), []byte("---\narguments:\n  - name: who\n    required: true\n---\nHello {{who}}!"), 0644)
+		tool := NewSkillTool(cfg)
+
+		if err := tool.Validate(map[string]interface{}{"skill": "greet"}); err == nil || !strings.Contains(err.Error(), "who") {
+			t.Errorf("expected missing argument error, got %v", err)
+		}
+		for _, args := range []interface{}{`{"who":"Ada"}`, map[string]interface{}{"who": "Ada"}, "Ada"} {
+			call := map[string]interface{}{"skill": "greet", "args": args}
+			if err := tool.Validate(call); err != nil {
+				t.Fatalf("args %v: %v", args, err)
+			}
+			res := tool.Execute(testCtx(cfg, call))
+			if res.Status != "success" || !strings.Contains(res.Output, "Hello Ada!") {
+				t.Errorf("args %v: got %q %s", args, res.Output, res.Error)
+			}
+		}
+	})
 }
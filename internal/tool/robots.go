@@ -0,0 +1,126 @@
+package tool
+
+import (
+	"bufio"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCrawlDelay 是 robots.txt 中 Crawl-delay 的生效上限，避免单个站点拖住整个抓取。
+const maxCrawlDelay = 10 * time.Second
+
+// robotsRules 是 robots.txt 中适用于本抓取器的规则组。
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow bool
+	// length 是原始模式的长度，最长匹配的规则生效
+	length int
+	re     *regexp.Regexp
+}
+
+// allowAll 用于 robots.txt 不存在（4xx）的站点。
+var allowAll = &robotsRules{}
+
+// disallowAll 用于 robots.txt 暂时无法获取（5xx、网络错误）的站点。
+var disallowAll = &robotsRules{rules: []robotsRule{{allow: false, length: 1, re: regexp.MustCompile("^/")}}}
+
+// parseRobots 解析 robots.txt，选取 User-agent 与 agent 匹配的规则组，没有时使用 * 组。
+// 支持 Allow/Disallow（含 * 与 $ 通配）和 Crawl-delay。
+func parseRobots(data, agent string) *robotsRules {
+	agent = strings.ToLower(agent)
+	var specific, wildcard *robotsRules
+	var current []*robotsRules
+	inAgents := false
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			// 连续的 User-agent 行共享同一个规则组
+			if !inAgents {
+				current = nil
+			}
+			inAgents = true
+			ua := strings.ToLower(value)
+			switch {
+			case ua == "*":
+				if wildcard == nil {
+					wildcard = &robotsRules{}
+				}
+				current = append(current, wildcard)
+			case ua != "" && strings.Contains(agent, ua):
+				if specific == nil {
+					specific = &robotsRules{}
+				}
+				current = append(current, specific)
+			}
+			continue
+		}
+		inAgents = false
+
+		for _, g := range current {
+			switch key {
+			case "allow", "disallow":
+				if value == "" {
+					continue // 空 Disallow 表示不限制
+				}
+				g.rules = append(g.rules, robotsRule{allow: key == "allow", length: len(value), re: robotsPattern(value)})
+			case "crawl-delay":
+				if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+					g.crawlDelay = time.Duration(secs * float64(time.Second))
+					if g.crawlDelay > maxCrawlDelay {
+						g.crawlDelay = maxCrawlDelay
+					}
+				}
+			}
+		}
+	}
+	if specific != nil {
+		return specific
+	}
+	if wildcard != nil {
+		return wildcard
+	}
+	return allowAll
+}
+
+// robotsPattern 将 robots.txt 路径模式转换为正则：* 匹配任意字符，结尾 $ 锚定末尾。
+func robotsPattern(p string) *regexp.Regexp {
+	anchored := strings.HasSuffix(p, "$")
+	p = strings.TrimSuffix(p, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(p), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// Allowed 判断路径（含查询串）是否允许抓取：最长匹配的规则生效，长度相同时 Allow 优先。
+func (r *robotsRules) Allowed(path string) bool {
+	best, allowed := -1, true
+	for _, rule := range r.rules {
+		if !rule.re.MatchString(path) {
+			continue
+		}
+		if rule.length > best || (rule.length == best && rule.allow) {
+			best, allowed = rule.length, rule.allow
+		}
+	}
+	return allowed
+}
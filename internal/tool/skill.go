@@ -1,9 +1,9 @@
 package tool
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -21,32 +21,95 @@ func NewSkillTool(config *types.Config) *SkillTool {
 
 func (t *SkillTool) Name() string { return "skill" }
 func (t *SkillTool) Description() string {
-	infos := skill.LoadInfos(t.config.RootDir)
+	infos := skill.For(t.config).Infos()
 	if len(infos) == 0 {
 		return "Load a specialized skill from skills directories"
 	}
 	var sb strings.Builder
 	sb.WriteString("Load a specialized skill from skills directories\n<available_skills>")
 	for _, s := range infos {
-		fmt.Fprintf(&sb, "\n  <skill><name>%s</name><description>%s</description><location>file://%s</location></skill>",
-			s.Name, s.Description, s.Location)
+		fmt.Fprintf(&sb, "\n  <skill><name>%s</name><description>%s</description>", s.Name, s.Description)
+		if s.WhenToUse != "" {
+			fmt.Fprintf(&sb, "<when_to_use>%s</when_to_use>", s.WhenToUse)
+		}
+		if hint := skill.ArgumentHint(s); hint != "" {
+			fmt.Fprintf(&sb, "<arguments>%s</arguments>", hint)
+		}
+		fmt.Fprintf(&sb, "<location>file://%s</location></skill>", s.Location)
 	}
 	sb.WriteString("\n</available_skills>")
 	return sb.String()
 }
 func (t *SkillTool) Parameters() interface{} {
 	return map[string]string{
-		"skill": "string (optional) - skill name to load; omit to list available skills",
+		"skill":   "string (optional) - skill name to load; omit to list available skills",
+		"args":    "object|string (optional) - skill arguments: an object such as {\"env\":\"prod\"} or a free-form string substituted into $ARGUMENTS",
+		"release": "bool (optional) - leave the active skill and lift its allowed-tools restriction",
+	}
+}
+
+func (t *SkillTool) Validate(args map[string]interface{}) error {
+	name, _ := args["skill"].(string)
+	if name == "" || boolArg(args, "release") {
+		return nil
+	}
+	info, ok := skill.For(t.config).Get(name)
+	if !ok {
+		// 未找到的 skill 由 Execute 报告
+		return nil
+	}
+	named, raw, err := skillArgs(args)
+	if err != nil {
+		return err
 	}
+	_, _, err = skill.ResolveArgs(info, named, raw)
+	return err
+}
+
+// skillArgs 解析 args 参数：对象或 JSON 对象字符串按名称传参，其余字符串作为自由格式参数。
+func skillArgs(args map[string]interface{}) (map[string]string, string, error) {
+	switch v := args["args"].(type) {
+	case nil:
+		return nil, "", nil
+	case map[string]interface{}:
+		named, err := stringMapArg(args, "args")
+		return named, "", err
+	case string:
+		if trimmed := strings.TrimSpace(v); strings.HasPrefix(trimmed, "{") {
+			var obj map[string]interface{}
+			if err := json.Unmarshal([]byte(trimmed), &obj); err == nil {
+				named := make(map[string]string, len(obj))
+				for k, val := range obj {
+					if s, ok := val.(string); ok {
+						named[k] = s
+					} else {
+						named[k] = fmt.Sprint(val)
+					}
+				}
+				return named, "", nil
+			}
+		}
+		return nil, v, nil
+	}
+	return nil, "", fmt.Errorf("args must be an object or a string")
 }
-func (t *SkillTool) Validate(args map[string]interface{}) error { return nil }
 
 func (t *SkillTool) Execute(ctx *Context) *Result {
 	result := &Result{StartTime: time.Now()}
 	skillName, _ := ctx.Args["skill"].(string)
 
+	if boolArg(ctx.Args, "release") {
+		result.Status = "success"
+		result.Output = "当前没有激活的 skill"
+		if name := ctx.Skills.Release(ctx.Session); name != "" {
+			result.Output = fmt.Sprintf("已退出 skill %q，工具限制已解除", name)
+		}
+		result.EndTime = time.Now()
+		return result
+	}
+
 	if skillName == "" {
-		infos := skill.LoadInfos(ctx.Config.RootDir)
+		infos := skill.For(ctx.Config).Infos()
 		if len(infos) == 0 {
 			result.Status = "success"
 			result.Output = "没有找到可用的 skills"
@@ -63,7 +126,7 @@ func (t *SkillTool) Execute(ctx *Context) *Result {
 		return result
 	}
 
-	info, ok := skill.Get(ctx.Config.RootDir, skillName)
+	info, ok := skill.For(ctx.Config).Get(skillName)
 	if !ok {
 		result.Status = "error"
 		result.Error = fmt.Sprintf("skill %q not found", skillName)
@@ -76,36 +139,31 @@ func (t *SkillTool) Execute(ctx *Context) *Result {
 		result.Error = err.Error()
 		return result
 	}
-
-	// list sibling files (up to 10, excluding SKILL.md)
-	var siblings []string
-	if entries, e := os.ReadDir(info.Dir); e == nil {
-		for _, entry := range entries {
-			if !entry.IsDir() && !strings.EqualFold(entry.Name(), "skill.md") {
-				siblings = append(siblings, entry.Name())
-				if len(siblings) == 10 {
-					break
-				}
-			}
-		}
+	named, raw, err := skillArgs(ctx.Args)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
 	}
-
-	var siblingPaths []string
-	for _, name := range siblings {
-		siblingPaths = append(siblingPaths, filepath.Join(info.Dir, name))
+	content, err := skill.Render(string(data), info, named, raw)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
 	}
 
+	ctx.Skills.Activate(ctx.Session, info.Name, info.AllowedTools)
+
 	var out strings.Builder
 	fmt.Fprintf(&out, "<skill_content name=%q>\n", skillName)
-	fmt.Fprintf(&out, "IMPORTANT: All file paths referenced in this skill must use absolute paths. The skill directory is: %s\n", info.Dir)
-	if len(siblingPaths) > 0 {
-		fmt.Fprintf(&out, "Available files in skill directory (use these absolute paths directly):\n")
-		for _, p := range siblingPaths {
-			fmt.Fprintf(&out, "  - %s\n", p)
-		}
+	fmt.Fprintf(&out, "Skill directory: %s\n", info.Dir)
+	out.WriteString(formatSkillResources(info))
+	fmt.Fprintf(&out, "Run scripts bundled with the skill with exec_cmd and skill=%q; the command then runs inside the skill directory.\n", info.Name)
+	if len(info.AllowedTools) > 0 {
+		fmt.Fprintf(&out, "While this skill is active only these tools may be called: %s (call skill with release=true when done)\n", strings.Join(info.AllowedTools, ", "))
 	}
 	out.WriteString("\n")
-	out.Write(data)
+	out.WriteString(content)
 	out.WriteString("\n</skill_content>")
 
 	result.Status = "success"
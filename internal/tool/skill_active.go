@@ -0,0 +1,68 @@
+package tool
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// skillTools 是不受 allowed-tools 限制的 skill 系列工具。
+var skillTools = map[string]bool{"skill": true, "skill_resource": true, "skill_search": true}
+
+type activeSkill struct {
+	name    string
+	allowed []string
+}
+
+// ActiveSkills 按会话记录当前激活的 skill。skill 声明了 allowed-tools 时，
+// 激活期间只允许调用这些工具；skillTools 中的工具始终可用，以便切换、释放 skill 和读取其资源。
+type ActiveSkills struct {
+	mu       sync.Mutex
+	sessions map[string]activeSkill
+}
+
+func NewActiveSkills() *ActiveSkills {
+	return &ActiveSkills{sessions: make(map[string]activeSkill)}
+}
+
+// Activate 将 name 设为 session 的当前 skill；allowed 为空表示不限制工具。
+func (a *ActiveSkills) Activate(session, name string, allowed []string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sessions[session] = activeSkill{name: name, allowed: allowed}
+}
+
+// Release 取消 session 的当前 skill，返回被取消的 skill 名称。
+func (a *ActiveSkills) Release(session string) string {
+	if a == nil {
+		return ""
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	name := a.sessions[session].name
+	delete(a.sessions, session)
+	return name
+}
+
+// Check 判断 session 当前是否允许调用 toolName。
+func (a *ActiveSkills) Check(session, toolName string) error {
+	if a == nil || skillTools[toolName] {
+		return nil
+	}
+	a.mu.Lock()
+	active, ok := a.sessions[session]
+	a.mu.Unlock()
+	if !ok || len(active.allowed) == 0 {
+		return nil
+	}
+	for _, name := range active.allowed {
+		if strings.EqualFold(name, toolName) {
+			return nil
+		}
+	}
+	return fmt.Errorf("tool %q is not allowed while skill %q is active (allowed-tools: %s); call skill with release=true to leave it",
+		toolName, active.name, strings.Join(active.allowed, ", "))
+}
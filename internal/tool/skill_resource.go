@@ -0,0 +1,163 @@
+package tool
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/afumu/openlink/internal/skill"
+	"github.com/afumu/openlink/internal/types"
+)
+
+// maxResourceRead 是 skill_resource 一次读取的文件大小上限。
+const maxResourceRead = 1 << 20
+
+// SkillResourceTool 按 skill 名称与相对路径读取 skill 目录下的资源文件。
+type SkillResourceTool struct {
+	config *types.Config
+}
+
+func NewSkillResourceTool(config *types.Config) *SkillResourceTool {
+	return &SkillResourceTool{config: config}
+}
+
+func (t *SkillResourceTool) Name() string { return "skill_resource" }
+func (t *SkillResourceTool) Description() string {
+	return "Read a file bundled with a skill, or list the skill's resources"
+}
+func (t *SkillResourceTool) Parameters() interface{} {
+	return map[string]string{
+		"skill":  "string (required) - skill name",
+		"path":   "string (optional) - file path relative to the skill directory, e.g. references/api.md; omit to list resources",
+		"offset": "number (optional) - line number to start reading from (1-based)",
+		"limit":  "number (optional) - max lines to read",
+	}
+}
+
+func (t *SkillResourceTool) Validate(args map[string]interface{}) error {
+	if name, _ := args["skill"].(string); name == "" {
+		return errors.New("skill is required")
+	}
+	return nil
+}
+
+func (t *SkillResourceTool) Execute(ctx *Context) *Result {
+	result := &Result{StartTime: time.Now()}
+	name, _ := ctx.Args["skill"].(string)
+	rel, _ := ctx.Args["path"].(string)
+	rel = strings.TrimSpace(rel)
+
+	info, ok := skill.For(ctx.Config).Get(name)
+	if !ok {
+		result.Status = "error"
+		result.Error = fmt.Sprintf("skill %q not found", name)
+		return result
+	}
+	if rel == "" || rel == "." {
+		result.Status = "success"
+		result.Output = formatSkillResources(info)
+		if result.Output == "" {
+			result.Output = fmt.Sprintf("skill %q 没有资源文件", info.Name)
+		}
+		result.EndTime = time.Now()
+		return result
+	}
+
+	path, err := skill.ResourcePath(info, rel)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	if fi.IsDir() {
+		result.Status = "error"
+		result.Error = fmt.Sprintf("%s is a directory; omit path to list the skill's resources", rel)
+		return result
+	}
+	data, err := io.ReadAll(io.LimitReader(f, maxResourceRead))
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	head := data
+	if len(head) > 8192 {
+		head = head[:8192]
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		result.Status = "success"
+		result.Output = fmt.Sprintf("%s 是二进制文件（%s），无法以文本读取", rel, skill.FormatSize(fi.Size()))
+		result.EndTime = time.Now()
+		return result
+	}
+
+	offset, limit := 1, MaxLines
+	if v, ok := intArg(ctx.Args, "offset"); ok && v >= 1 {
+		offset = v
+	}
+	if v, ok := intArg(ctx.Args, "limit"); ok && v >= 1 {
+		limit = min(v, MaxLines)
+	}
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n"), "\n")
+	total := len(lines)
+	if offset > total {
+		lines = nil
+	} else {
+		lines = lines[offset-1:]
+	}
+	var out []string
+	size := 0
+	for _, line := range lines {
+		if len(out) == limit || size+len(line)+1 > MaxBytes {
+			break
+		}
+		out = append(out, line)
+		size += len(line) + 1
+	}
+
+	output := strings.Join(out, "\n")
+	if output == "" {
+		output = "empty"
+	}
+	if next := offset + len(out); next <= total || int64(len(data)) < fi.Size() {
+		output += fmt.Sprintf("\n[truncated, %d total lines, use offset=%d to continue]", total, next)
+	}
+	result.Status = "success"
+	result.Output = output
+	result.EndTime = time.Now()
+	return result
+}
+
+// formatSkillResources 生成 skill 的资源清单，没有资源时返回空字符串。
+func formatSkillResources(info skill.Info) string {
+	resources, truncated, err := skill.Resources(info)
+	if err != nil || len(resources) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Resources of skill %q (read with skill_resource, skill=%q and path=<relative path>):\n", info.Name, info.Name)
+	for _, r := range resources {
+		fmt.Fprintf(&b, "  - %s (%s)\n", r.Path, skill.FormatSize(r.Size))
+	}
+	if truncated {
+		fmt.Fprintf(&b, "  ... only the first %d files are listed\n", skill.MaxResources)
+	}
+	return b.String()
+}
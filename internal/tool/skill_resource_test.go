@@ -0,0 +1,99 @@
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/afumu/openlink/internal/types"
+)
+
+func TestSkillResourceTool(t *testing.T) {
+	cfg := &types.Config{RootDir: t.TempDir(), Timeout: 10}
+	dir := filepath.Join(cfg.RootDir, ".skills", "pdf")
+	os.MkdirAll(filepath.Join(dir, "references"), 0755)
+	os.MkdirAll(filepath.Join(dir, "scripts"), 0755)
+	os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: pdf\n---\nbody"), 0644)
+	os.WriteFile(filepath.Join(dir, "references", "forms.md"), []byte("line1\nline2\nline3\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "scripts", "fill.py"), []byte("print(1)\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "logo.png"), []byte("\x89PNG\x00\x00"), 0644)
+	tool := NewSkillResourceTool(cfg)
+
+	t.Run("lists resources recursively", func(t *testing.T) {
+		res := tool.Execute(testCtx(cfg, map[string]interface{}{"skill": "pdf"}))
+		for _, want := range []string{"references/forms.md (18 B)", "scripts/fill.py", "logo.png"} {
+			if !strings.Contains(res.Output, want) {
+				t.Errorf("missing %q in %q", want, res.Output)
+			}
+		}
+	})
+
+	t.Run("skill tool output includes the manifest", func(t *testing.T) {
+		res := NewSkillTool(cfg).Execute(testCtx(cfg, map[string]interface{}{"skill": "pdf"}))
+		if !strings.Contains(res.Output, "scripts/fill.py") || !strings.Contains(res.Output, "skill_resource") {
+			t.Errorf("got %q", res.Output)
+		}
+	})
+
+	t.Run("reads file by relative path", func(t *testing.T) {
+		res := tool.Execute(testCtx(cfg, map[string]interface{}{"skill": "pdf", "path": "references/forms.md", "offset": "2", "limit": "1"}))
+		if res.Status != "success" || !strings.HasPrefix(res.Output, "line2\n[truncated, 3 total lines, use offset=3") {
+			t.Errorf("got %q %s", res.Output, res.Error)
+		}
+	})
+
+	t.Run("binary files are summarized", func(t *testing.T) {
+		res := tool.Execute(testCtx(cfg, map[string]interface{}{"skill": "pdf", "path": "logo.png"}))
+		if !strings.Contains(res.Output, "二进制") {
+			t.Errorf("got %q", res.Output)
+		}
+	})
+
+	t.Run("path traversal rejected", func(t *testing.T) {
+		res := tool.Execute(testCtx(cfg, map[string]interface{}{"skill": "pdf", "path": "../../../etc/passwd"}))
+		if res.Status != "error" {
+			t.Errorf("expected error, got %q", res.Output)
+		}
+	})
+
+	t.Run("exec_cmd runs inside the skill directory", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("uses sh")
+		}
+		res := NewExecCmdTool(cfg).Execute(testCtx(cfg, map[string]interface{}{"command": "pwd; ls scripts", "skill": "pdf"}))
+		if res.Status != "success" || !strings.Contains(res.Output, "fill.py") || !strings.Contains(res.Output, filepath.Join(".skills", "pdf")) {
+			t.Errorf("got %q %s", res.Output, res.Error)
+		}
+		res = NewExecCmdTool(cfg).Execute(testCtx(cfg, map[string]interface{}{"command": "pwd", "skill": "nope"}))
+		if res.Status != "error" {
+			t.Error("expected error for unknown skill")
+		}
+	})
+}
+
+func TestSkillSearchTool(t *testing.T) {
+	cfg := &types.Config{RootDir: t.TempDir(), Timeout: 10}
+	for name, front := range map[string]string{
+		"deploy": "description: Deploy the service to production\narguments:\n  - name: env\n    required: true",
+		"pdf":    "description: Fill PDF forms\nkeywords: [acroform]",
+	} {
+		dir := filepath.Join(cfg.RootDir, ".skills", name)
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\n"+front+"\n---\nbody"), 0644)
+	}
+	tool := NewSkillSearchTool(cfg)
+
+	if err := tool.Validate(map[string]interface{}{"query": " "}); err == nil {
+		t.Error("expected error for empty query")
+	}
+	res := tool.Execute(testCtx(cfg, map[string]interface{}{"query": "ship it to production"}))
+	if res.Status != "success" || !strings.Contains(res.Output, "1. deploy <env>") || strings.Contains(res.Output, "pdf") {
+		t.Errorf("got %q", res.Output)
+	}
+	res = tool.Execute(testCtx(cfg, map[string]interface{}{"query": "kubernetes"}))
+	if res.Output != "没有找到相关的 skill" {
+		t.Errorf("got %q", res.Output)
+	}
+}
@@ -0,0 +1,83 @@
+package tool
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/afumu/openlink/internal/skill"
+	"github.com/afumu/openlink/internal/types"
+)
+
+const (
+	defaultSkillSearchLimit = 5
+	maxSkillSearchLimit     = 20
+)
+
+// SkillSearchTool 按相关度在本地 skill 中查找与任务匹配的 skill。
+type SkillSearchTool struct {
+	config *types.Config
+}
+
+func NewSkillSearchTool(config *types.Config) *SkillSearchTool {
+	return &SkillSearchTool{config: config}
+}
+
+func (t *SkillSearchTool) Name() string { return "skill_search" }
+func (t *SkillSearchTool) Description() string {
+	return "Find skills relevant to a task by name, description, tags and keywords"
+}
+func (t *SkillSearchTool) Parameters() interface{} {
+	return map[string]string{
+		"query": "string (required) - what the user wants to do, e.g. the user's message",
+		"limit": fmt.Sprintf("number (optional) - max results (default %d, max %d)", defaultSkillSearchLimit, maxSkillSearchLimit),
+	}
+}
+
+func (t *SkillSearchTool) Validate(args map[string]interface{}) error {
+	if q, _ := args["query"].(string); strings.TrimSpace(q) == "" {
+		return errors.New("query is required")
+	}
+	if n, ok := intArg(args, "limit"); ok && n <= 0 {
+		return errors.New("limit must be positive")
+	}
+	return nil
+}
+
+func (t *SkillSearchTool) Execute(ctx *Context) *Result {
+	result := &Result{StartTime: time.Now()}
+	query, _ := ctx.Args["query"].(string)
+	limit := defaultSkillSearchLimit
+	if n, ok := intArg(ctx.Args, "limit"); ok {
+		limit = min(n, maxSkillSearchLimit)
+	}
+
+	result.Status = "success"
+	result.Output = formatSkillMatches(skill.For(ctx.Config).Search(query, limit))
+	result.EndTime = time.Now()
+	return result
+}
+
+// formatSkillMatches 输出匹配到的 skill 及其描述，供模型决定加载哪一个。
+func formatSkillMatches(matches []skill.Match) string {
+	if len(matches) == 0 {
+		return "没有找到相关的 skill"
+	}
+	var sb strings.Builder
+	sb.WriteString("Relevant skills (load one with the skill tool):")
+	for i, m := range matches {
+		fmt.Fprintf(&sb, "\n%d. %s", i+1, m.Info.Name)
+		if hint := skill.ArgumentHint(m.Info); hint != "" {
+			sb.WriteString(" " + hint)
+		}
+		fmt.Fprintf(&sb, " (score %.2f)", m.Score)
+		if m.Info.Description != "" {
+			sb.WriteString("\n   " + m.Info.Description)
+		}
+		if m.Info.WhenToUse != "" {
+			sb.WriteString("\n   When to use: " + m.Info.WhenToUse)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,244 @@
+package tool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TodosDir 是待办事项在工作目录下的存放位置，每个会话一个文件。
+const TodosDir = ".openlink/todos"
+
+const maxTodos = 100
+
+// 待办状态。
+const (
+	TodoPending    = "pending"
+	TodoInProgress = "in_progress"
+	TodoDone       = "done"
+)
+
+// 待办优先级。
+const (
+	TodoHigh   = "high"
+	TodoMedium = "medium"
+	TodoLow    = "low"
+)
+
+// Todo 是一条待办事项。
+type Todo struct {
+	ID        string    `json:"id"`
+	Content   string    `json:"content"`
+	Status    string    `json:"status"`
+	Priority  string    `json:"priority"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// todoFile 是单个会话的待办文件内容。
+type todoFile struct {
+	Session string `json:"session"`
+	Todos   []Todo `json:"todos"`
+}
+
+// TodoStore 按会话读写 <root>/.openlink/todos/ 下的待办文件。
+type TodoStore struct {
+	dir string
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+func NewTodoStore(rootDir string) *TodoStore {
+	return &TodoStore{dir: filepath.Join(rootDir, filepath.FromSlash(TodosDir)), now: time.Now}
+}
+
+// path 返回会话对应的文件路径；会话标识是页面地址，取哈希作为文件名。
+func (s *TodoStore) path(session string) string {
+	sum := sha256.Sum256([]byte(session))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// Load 读取会话的待办列表，文件不存在时返回空列表。
+func (s *TodoStore) Load(session string) ([]Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(session)
+}
+
+func (s *TodoStore) load(session string) ([]Todo, error) {
+	data, err := os.ReadFile(s.path(session))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f todoFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid todo file %s: %w", s.path(session), err)
+	}
+	return f.Todos, nil
+}
+
+// Replace 用 todos 整体替换会话的待办列表。与已有条目 ID 相同的保留创建时间，
+// 内容、状态或优先级有变化时更新修改时间。返回保存后的列表。
+func (s *TodoStore) Replace(session string, todos []Todo) ([]Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, err := s.load(session)
+	if err != nil {
+		return nil, err
+	}
+	prev := make(map[string]Todo, len(old))
+	for _, t := range old {
+		prev[t.ID] = t
+	}
+
+	now := s.now().UTC().Truncate(time.Second)
+	for i := range todos {
+		t := &todos[i]
+		t.CreatedAt, t.UpdatedAt = now, now
+		if p, ok := prev[t.ID]; ok {
+			t.CreatedAt = p.CreatedAt
+			if p.Content == t.Content && p.Status == t.Status && p.Priority == t.Priority {
+				t.UpdatedAt = p.UpdatedAt
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(todoFile{Session: session, Todos: todos}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(s.path(session), data, defaultFileMode); err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+// parseTodos 解析并校验 todo_write 的 todos 参数：数组或 JSON 数组字符串，
+// 元素为对象（content 必填）或直接是任务内容的字符串。缺省状态为 pending、优先级为 medium，
+// 缺少 id 的条目按位置编号。
+func parseTodos(raw interface{}) ([]Todo, error) {
+	if s, ok := raw.(string); ok {
+		if err := json.Unmarshal([]byte(strings.TrimSpace(s)), &raw); err != nil {
+			return nil, fmt.Errorf("todos must be a JSON array: %w", err)
+		}
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, errors.New("todos must be an array")
+	}
+	if len(items) > maxTodos {
+		return nil, fmt.Errorf("too many todos (%d, max %d)", len(items), maxTodos)
+	}
+
+	todos := make([]Todo, 0, len(items))
+	seen := map[string]bool{}
+	inProgress := 0
+	for i, item := range items {
+		var t Todo
+		switch v := item.(type) {
+		case string:
+			t.Content = v
+		case map[string]interface{}:
+			if id, ok := v["id"]; ok && id != nil {
+				t.ID = strings.TrimSpace(fmt.Sprint(id))
+			}
+			t.Content, _ = v["content"].(string)
+			t.Status, _ = v["status"].(string)
+			t.Priority, _ = v["priority"].(string)
+		default:
+			return nil, fmt.Errorf("todos[%d]: expected an object", i)
+		}
+
+		t.Content = strings.TrimSpace(t.Content)
+		if t.Content == "" {
+			return nil, fmt.Errorf("todos[%d]: content is required", i)
+		}
+		if t.ID == "" {
+			t.ID = strconv.Itoa(i + 1)
+		}
+		if seen[t.ID] {
+			return nil, fmt.Errorf("todos[%d]: duplicate id %q", i, t.ID)
+		}
+		seen[t.ID] = true
+
+		switch t.Status = strings.ToLower(strings.TrimSpace(t.Status)); t.Status {
+		case "":
+			t.Status = TodoPending
+		case "completed", "complete":
+			t.Status = TodoDone
+		case "in-progress", "doing":
+			t.Status = TodoInProgress
+		case TodoPending, TodoInProgress, TodoDone:
+		default:
+			return nil, fmt.Errorf("todos[%d]: invalid status %q (expected pending, in_progress or done)", i, t.Status)
+		}
+		if t.Status == TodoInProgress {
+			inProgress++
+		}
+
+		switch t.Priority = strings.ToLower(strings.TrimSpace(t.Priority)); t.Priority {
+		case "":
+			t.Priority = TodoMedium
+		case TodoHigh, TodoMedium, TodoLow:
+		default:
+			return nil, fmt.Errorf("todos[%d]: invalid priority %q (expected high, medium or low)", i, t.Priority)
+		}
+		todos = append(todos, t)
+	}
+	if inProgress > 1 {
+		return nil, fmt.Errorf("only one todo may be in_progress at a time (got %d)", inProgress)
+	}
+	return todos, nil
+}
+
+// RenderTodos 将待办列表渲染为 Markdown 清单，空列表返回空字符串。
+func RenderTodos(todos []Todo) string {
+	if len(todos) == 0 {
+		return ""
+	}
+	done := 0
+	var b strings.Builder
+	for _, t := range todos {
+		mark := " "
+		switch t.Status {
+		case TodoDone:
+			mark = "x"
+			done++
+		case TodoInProgress:
+			mark = "~"
+		}
+		fmt.Fprintf(&b, "- [%s] %s. %s", mark, t.ID, t.Content)
+		if t.Priority == TodoHigh || t.Priority == TodoLow {
+			fmt.Fprintf(&b, "（%s）", t.Priority)
+		}
+		if t.Status == TodoInProgress {
+			b.WriteString(" ← 进行中")
+		}
+		b.WriteString("\n")
+	}
+	return fmt.Sprintf("待办事项（已完成 %d/%d）：\n%s", done, len(todos), strings.TrimRight(b.String(), "\n"))
+}
+
+// HasOpenTodos 判断是否还有未完成的待办。
+func HasOpenTodos(todos []Todo) bool {
+	for _, t := range todos {
+		if t.Status != TodoDone {
+			return true
+		}
+	}
+	return false
+}
@@ -1,11 +1,7 @@
 package tool
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
-	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/afumu/openlink/internal/types"
@@ -13,17 +9,21 @@ import (
 
 type TodoWriteTool struct {
 	config *types.Config
+	store  *TodoStore
 }
 
-func NewTodoWriteTool(config *types.Config) *TodoWriteTool {
-	return &TodoWriteTool{config: config}
+// NewTodoWriteTool 创建 todo_write 工具；store 需与 todo_read 和 /todos 共用同一个实例，读写才会串行。
+func NewTodoWriteTool(config *types.Config, store *TodoStore) *TodoWriteTool {
+	return &TodoWriteTool{config: config, store: store}
 }
 
-func (t *TodoWriteTool) Name() string        { return "todo_write" }
-func (t *TodoWriteTool) Description() string { return "Write task list to .todos.json" }
+func (t *TodoWriteTool) Name() string { return "todo_write" }
+func (t *TodoWriteTool) Description() string {
+	return "Replace the task list of the current conversation"
+}
 func (t *TodoWriteTool) Parameters() interface{} {
 	return map[string]string{
-		"todos": "array (required) - full list of todo items to save",
+		"todos": "array (required) - full list of todo items: {id, content, status: pending|in_progress|done, priority: high|medium|low}",
 	}
 }
 
@@ -31,27 +31,63 @@ func (t *TodoWriteTool) Validate(args map[string]interface{}) error {
 	if _, ok := args["todos"]; !ok {
 		return errors.New("todos is required")
 	}
-	return nil
+	_, err := parseTodos(args["todos"])
+	return err
 }
 
 func (t *TodoWriteTool) Execute(ctx *Context) *Result {
 	result := &Result{StartTime: time.Now()}
-	todos := ctx.Args["todos"]
-	data, err := json.MarshalIndent(todos, "", "  ")
+	todos, err := parseTodos(ctx.Args["todos"])
 	if err != nil {
 		result.Status = "error"
 		result.Error = err.Error()
 		return result
 	}
-	p := filepath.Join(ctx.Config.RootDir, ".todos.json")
-	if err := os.WriteFile(p, data, 0644); err != nil {
+	todos, err = t.store.Replace(ctx.Session, todos)
+	if err != nil {
 		result.Status = "error"
 		result.Error = err.Error()
 		return result
 	}
-	items, _ := todos.([]interface{})
 	result.Status = "success"
-	result.Output = fmt.Sprintf("已保存 %d 个任务", len(items))
+	result.Output = "已保存待办事项\n\n" + RenderTodos(todos)
+	if len(todos) == 0 {
+		result.Output = "已清空待办事项"
+	}
+	result.EndTime = time.Now()
+	return result
+}
+
+type TodoReadTool struct {
+	config *types.Config
+	store  *TodoStore
+}
+
+func NewTodoReadTool(config *types.Config, store *TodoStore) *TodoReadTool {
+	return &TodoReadTool{config: config, store: store}
+}
+
+func (t *TodoReadTool) Name() string { return "todo_read" }
+func (t *TodoReadTool) Description() string {
+	return "Read the task list of the current conversation"
+}
+func (t *TodoReadTool) Parameters() interface{} { return map[string]string{} }
+
+func (t *TodoReadTool) Validate(args map[string]interface{}) error { return nil }
+
+func (t *TodoReadTool) Execute(ctx *Context) *Result {
+	result := &Result{StartTime: time.Now()}
+	todos, err := t.store.Load(ctx.Session)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = "success"
+	result.Output = RenderTodos(todos)
+	if result.Output == "" {
+		result.Output = "当前没有待办事项"
+	}
 	result.EndTime = time.Now()
 	return result
 }
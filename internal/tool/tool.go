@@ -1,6 +1,8 @@
 package tool
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -18,8 +20,34 @@ type Tool interface {
 }
 
 type Context struct {
-	Args   map[string]interface{}
-	Config *types.Config
+	Args      map[string]interface{}
+	Config    *types.Config
+	Session   string
+	Files     *FileTracker
+	Questions *QuestionBroker
+	Skills    *ActiveSkills
+	// Done 在调用被取消（如扩展断开请求）时关闭，nil 表示不会被取消
+	Done <-chan struct{}
+}
+
+// WithToolTimeout 返回一个在调用被取消（Done 关闭）或超过配置的 Timeout 后取消的 context，
+// 供发起网络请求的工具使用；未配置 Timeout 时为 30 秒。
+func (c *Context) WithToolTimeout() (context.Context, context.CancelFunc) {
+	timeout := 30 * time.Second
+	if c.Config != nil && c.Config.Timeout > 0 {
+		timeout = time.Duration(c.Config.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	if c.Done != nil {
+		go func() {
+			select {
+			case <-c.Done:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+	return ctx, cancel
 }
 
 type Result struct {
@@ -37,6 +65,16 @@ type ToolInfo struct {
 	Parameters  interface{} `json:"parameters,omitempty"`
 }
 
+// checkWritable 拒绝写入项目配置文件：其中的 http_allow_hosts 决定 http_request 能访问哪些主机，
+// 只能由用户修改。path 为已解析的绝对路径。
+func checkWritable(rootDir, path string) error {
+	cfg, err := security.SafePath(rootDir, types.ProjectConfigFile)
+	if err == nil && path == cfg {
+		return fmt.Errorf("%s can only be edited by the user", types.ProjectConfigFile)
+	}
+	return nil
+}
+
 // resolveAbsPath validates an absolute path against RootDir and common allowed roots (~/.claude, ~/.openlink, ~/.agent).
 func resolveAbsPath(path, rootDir string) (string, error) {
 	home, _ := os.UserHomeDir()
@@ -26,8 +26,11 @@ func TestToolMeta(t *testing.T) {
 		NewReadFileTool(cfg),
 		NewWriteFileTool(cfg),
 		NewSkillTool(cfg),
-		NewTodoWriteTool(cfg),
-		NewWebFetchTool(),
+		NewTodoWriteTool(cfg, NewTodoStore(cfg.RootDir)),
+		NewWebFetchTool(cfg),
+		NewHTTPRequestTool(cfg),
+		NewWebCrawlTool(cfg),
+		NewWebSearchTool(cfg),
 	}
 
 	for _, tool := range tools {
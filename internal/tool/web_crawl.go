@@ -0,0 +1,455 @@
+package tool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/afumu/openlink/internal/security"
+	"github.com/afumu/openlink/internal/types"
+	"github.com/afumu/openlink/internal/webcache"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// DocsDir 是抓取的文档语料相对工作目录的存放位置，每个站点一个子目录。
+const DocsDir = ".openlink/docs"
+
+const (
+	crawlUserAgent       = "openlink-crawler/1.0 (+https://github.com/afumu/openlink)"
+	defaultCrawlDepth    = 2
+	maxCrawlDepth        = 5
+	defaultCrawlPages    = 30
+	maxCrawlPages        = 500
+	defaultCrawlDelay    = 500 * time.Millisecond
+	maxCrawlPageBytes    = 2 * 1024 * 1024
+	crawlOutputFileLimit = 30
+)
+
+// crawlPageExts 是会被当作网页抓取的路径扩展名，其余（图片、压缩包、PDF 等）直接跳过。
+var crawlPageExts = map[string]bool{
+	"": true, ".html": true, ".htm": true, ".xhtml": true, ".shtml": true,
+	".php": true, ".asp": true, ".aspx": true, ".jsp": true,
+}
+
+var unsafeNameRe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// WebCrawlTool 从起始 URL 出发，按广度优先抓取同站点、同路径前缀下的页面，
+// 转换为 Markdown 后保存到工作目录的 .openlink/docs/<host>/ 下，供 grep、glob 与 read_file 使用。
+type WebCrawlTool struct {
+	config *types.Config
+	// policy 与 web_fetch 相同，nil 表示只允许公网地址
+	policy security.IPPolicy
+	cache  *webcache.Cache
+}
+
+func NewWebCrawlTool(config *types.Config) *WebCrawlTool {
+	t := &WebCrawlTool{config: config}
+	if dir, err := webcache.DefaultDir(); err == nil {
+		t.cache = webcache.New(dir)
+		t.cache.Offline = config.Offline
+	}
+	return t
+}
+
+func (t *WebCrawlTool) Name() string { return "web_crawl" }
+func (t *WebCrawlTool) Description() string {
+	return "Crawl linked documentation pages from a URL into a local Markdown corpus under .openlink/docs/"
+}
+func (t *WebCrawlTool) Parameters() interface{} {
+	return map[string]string{
+		"url":       "string (required) - start URL",
+		"depth":     "number (optional) - link depth to follow from the start page (default 2, max 5)",
+		"max_pages": "number (optional) - maximum pages to save (default 30, max 500)",
+		"prefix":    "string (optional) - only follow links whose path starts with this prefix (default: the start page's directory)",
+		"delay_ms":  "number (optional) - minimum delay between requests to the same host (default 500; robots.txt Crawl-delay wins if larger)",
+	}
+}
+
+func (t *WebCrawlTool) Validate(args map[string]interface{}) error {
+	rawURL, ok := args["url"].(string)
+	if !ok || rawURL == "" {
+		return errors.New("url is required")
+	}
+	if p, _ := args["prefix"].(string); p != "" && !strings.HasPrefix(p, "/") {
+		return errors.New("prefix must be an absolute URL path such as /docs/")
+	}
+	if err := checkOffline(t.config, t.cache); err != nil {
+		return err
+	}
+	return checkFetchURL(rawURL, t.policy, t.cache != nil && t.cache.Offline)
+}
+
+// crawledPage 是一个已保存的页面。
+type crawledPage struct {
+	url   string
+	title string
+	file  string // 相对站点目录的路径
+}
+
+// crawler 保存一次抓取的状态。
+type crawler struct {
+	client   *http.Client
+	start    *url.URL
+	prefix   string
+	outDir   string
+	delay    time.Duration
+	deadline time.Time
+
+	robots  map[string]*robotsRules
+	lastHit map[string]time.Time
+	files   map[string]bool
+	saved   map[string]bool
+
+	pages        []crawledPage
+	robotsDenied int
+	nonHTML      int
+	failed       int
+	stopped      string
+}
+
+type crawlItem struct {
+	u     *url.URL
+	depth int
+}
+
+func (t *WebCrawlTool) Execute(ctx *Context) *Result {
+	result := &Result{StartTime: time.Now()}
+	rawURL, _ := ctx.Args["url"].(string)
+	start, err := url.Parse(rawURL)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	start.Fragment = ""
+
+	depth := defaultCrawlDepth
+	if v, ok := intArg(ctx.Args, "depth"); ok && v >= 0 {
+		depth = min(v, maxCrawlDepth)
+	}
+	maxPages := defaultCrawlPages
+	if v, ok := intArg(ctx.Args, "max_pages"); ok && v >= 1 {
+		maxPages = min(v, maxCrawlPages)
+	}
+	delay := defaultCrawlDelay
+	if v, ok := intArg(ctx.Args, "delay_ms"); ok && v >= 0 {
+		delay = time.Duration(v) * time.Millisecond
+	}
+	prefix, _ := ctx.Args["prefix"].(string)
+	if prefix == "" {
+		prefix = crawlPrefix(start.Path)
+	}
+
+	siteDir := strings.ToLower(unsafeNameRe.ReplaceAllString(start.Host, "_"))
+	outDir, err := security.SafePath(ctx.Config.RootDir, filepath.Join(DocsDir, siteDir))
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+
+	client := security.NewGuardedClient(30*time.Second, t.policy)
+	if t.cache != nil {
+		client.Transport = t.cache.Transport(client.Transport)
+	}
+	c := &crawler{
+		client:  client,
+		start:   start,
+		prefix:  prefix,
+		outDir:  outDir,
+		delay:   delay,
+		robots:  map[string]*robotsRules{},
+		lastHit: map[string]time.Time{},
+		files:   map[string]bool{},
+		saved:   map[string]bool{},
+	}
+	// 作为工具调用时只用调用超时的九成，留出保存与返回的时间；命令行调用（Timeout 为 0）不限时
+	if ctx.Config.Timeout > 0 {
+		c.deadline = result.StartTime.Add(time.Duration(ctx.Config.Timeout) * time.Second * 9 / 10)
+	}
+	c.run(depth, maxPages)
+
+	if len(c.pages) > 0 {
+		if err := c.writeIndex(); err != nil {
+			result.Status = "error"
+			result.Error = err.Error()
+			return result
+		}
+	} else if c.failed > 0 || c.robotsDenied > 0 {
+		result.Status = "error"
+		result.Error = fmt.Sprintf("no pages saved (%d failed, %d disallowed by robots.txt)", c.failed, c.robotsDenied)
+		return result
+	}
+
+	result.Status = "success"
+	result.Output = c.summary(displayPath(workspaceRoot(ctx.Config.RootDir), outDir))
+	result.EndTime = time.Now()
+	return result
+}
+
+// run 按广度优先抓取，直到队列为空、达到页数上限或时间预算用完。
+func (c *crawler) run(maxDepth, maxPages int) {
+	seen := map[string]bool{c.start.String(): true}
+	queue := []crawlItem{{u: c.start, depth: 0}}
+	for len(queue) > 0 {
+		if len(c.pages) >= maxPages {
+			c.stopped = fmt.Sprintf("达到 max_pages=%d 上限", maxPages)
+			return
+		}
+		if !c.deadline.IsZero() && time.Now().After(c.deadline) {
+			c.stopped = "达到本次调用的时间预算"
+			return
+		}
+		item := queue[0]
+		queue = queue[1:]
+
+		if !c.robotsFor(item.u).Allowed(item.u.RequestURI()) {
+			c.robotsDenied++
+			continue
+		}
+		links, ok := c.fetch(item.u)
+		if !ok || item.depth >= maxDepth {
+			continue
+		}
+		for _, l := range links {
+			if key := l.String(); !seen[key] && c.inScope(l) {
+				seen[key] = true
+				queue = append(queue, crawlItem{u: l, depth: item.depth + 1})
+			}
+		}
+	}
+}
+
+// crawlPrefix 返回起始页所在目录作为默认路径前缀：/docs/intro.html → /docs/。
+func crawlPrefix(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if !strings.HasSuffix(p, "/") {
+		p = path.Dir(p)
+	}
+	return strings.TrimSuffix(p, "/") + "/"
+}
+
+// inScope 判断链接是否属于本次抓取：同一主机、路径前缀匹配且看起来是网页。
+func (c *crawler) inScope(u *url.URL) bool {
+	if (u.Scheme != "http" && u.Scheme != "https") || !strings.EqualFold(u.Host, c.start.Host) {
+		return false
+	}
+	if !strings.HasPrefix(u.Path, c.prefix) && u.Path+"/" != c.prefix {
+		return false
+	}
+	return crawlPageExts[strings.ToLower(path.Ext(u.Path))]
+}
+
+// wait 保证对同一主机的相邻请求间隔不小于 delay 与 robots.txt Crawl-delay 中的较大者。
+func (c *crawler) wait(host string) {
+	delay := c.delay
+	if r := c.robots[host]; r != nil && r.crawlDelay > delay {
+		delay = r.crawlDelay
+	}
+	if last, ok := c.lastHit[host]; ok {
+		if d := time.Until(last.Add(delay)); d > 0 {
+			time.Sleep(d)
+		}
+	}
+	c.lastHit[host] = time.Now()
+}
+
+func (c *crawler) get(u *url.URL) (*http.Response, error) {
+	c.wait(u.Host)
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", crawlUserAgent)
+	return c.client.Do(req)
+}
+
+// robotsFor 返回主机的 robots.txt 规则，首次访问时抓取：不存在（4xx）时全部允许，
+// 服务端错误或网络错误时全部禁止。
+func (c *crawler) robotsFor(u *url.URL) *robotsRules {
+	if r, ok := c.robots[u.Host]; ok {
+		return r
+	}
+	rules := disallowAll
+	resp, err := c.get(&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"})
+	if err == nil {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512*1024))
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			rules = parseRobots(string(data), crawlUserAgent)
+		case resp.StatusCode >= 400 && resp.StatusCode < 500:
+			rules = allowAll
+		}
+	}
+	c.robots[u.Host] = rules
+	return rules
+}
+
+// fetch 抓取并保存一个页面，返回页面中的链接。
+func (c *crawler) fetch(u *url.URL) ([]*url.URL, bool) {
+	resp, err := c.get(u)
+	if err != nil {
+		log.Printf("[WebCrawl] ❌ %s: %v\n", u, err)
+		c.failed++
+		return nil, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("[WebCrawl] ❌ %s: %s\n", u, resp.Status)
+		c.failed++
+		return nil, false
+	}
+	// 重定向到站外或已保存过的页面（如 /docs → /docs/）时不再重复保存
+	final := resp.Request.URL
+	if !strings.EqualFold(final.Host, c.start.Host) || c.saved[final.String()] {
+		return nil, false
+	}
+	c.saved[final.String()] = true
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCrawlPageBytes))
+	if err != nil {
+		c.failed++
+		return nil, false
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if !isHTMLContent(contentType, body) {
+		c.nonHTML++
+		return nil, false
+	}
+
+	src := decodeHTMLBody(body, contentType)
+	md := pageToMarkdown(src, final, false)
+	rel := c.docFile(final)
+	data := fmt.Sprintf("<!-- source: %s -->\n\n%s\n", final, md)
+	if err := writeFileAtomic(filepath.Join(c.outDir, filepath.FromSlash(rel)), []byte(data), defaultFileMode); err != nil {
+		log.Printf("[WebCrawl] ❌ %s: %v\n", final, err)
+		c.failed++
+		return nil, false
+	}
+	log.Printf("[WebCrawl] ✅ %s → %s\n", final, rel)
+	c.pages = append(c.pages, crawledPage{url: final.String(), title: markdownTitle(md, final), file: rel})
+	return extractLinks(src, final), true
+}
+
+// docFile 将页面 URL 映射为站点目录内的 Markdown 文件路径：
+// /guide/ → guide/index.md，/api/net.html → api/net.md，带查询串时追加短哈希。
+func (c *crawler) docFile(u *url.URL) string {
+	p := strings.Trim(u.Path, "/")
+	if p == "" || strings.HasSuffix(u.Path, "/") {
+		p = path.Join(p, "index")
+	} else {
+		p = strings.TrimSuffix(p, path.Ext(p))
+	}
+	segs := strings.Split(p, "/")
+	for i, s := range segs {
+		s = unsafeNameRe.ReplaceAllString(s, "_")
+		if s == "" || s == "." || s == ".." {
+			s = "_"
+		}
+		segs[i] = s
+	}
+	p = strings.Join(segs, "/")
+	if u.RawQuery != "" {
+		sum := sha256.Sum256([]byte(u.RawQuery))
+		p += "_" + hex.EncodeToString(sum[:4])
+	}
+	// 不同 URL 映射到同一文件时（如 /a 与 /a.html）追加序号
+	file := p + ".md"
+	for i := 2; c.files[file]; i++ {
+		file = fmt.Sprintf("%s_%d.md", p, i)
+	}
+	c.files[file] = true
+	return file
+}
+
+// extractLinks 返回页面中所有 <a href> 解析后的绝对 URL（去掉片段），遵循 <base href>。
+func extractLinks(src string, pageURL *url.URL) []*url.URL {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return nil
+	}
+	base := pageURL
+	if b := findElement(doc, atom.Base); b != nil {
+		if u, err := pageURL.Parse(attr(b, "href")); err == nil && attr(b, "href") != "" {
+			base = u
+		}
+	}
+	var links []*url.URL
+	walkNodes(doc, func(n *html.Node) bool {
+		if n.DataAtom != atom.A {
+			return true
+		}
+		href := strings.TrimSpace(attr(n, "href"))
+		if href == "" || strings.HasPrefix(href, "#") || attr(n, "rel") == "nofollow" {
+			return true
+		}
+		if u, err := base.Parse(href); err == nil {
+			u.Fragment = ""
+			u.RawFragment = ""
+			links = append(links, u)
+		}
+		return true
+	})
+	return links
+}
+
+// markdownTitle 取 Markdown 的第一个标题，没有时使用 URL 路径。
+func markdownTitle(md string, u *url.URL) string {
+	for _, line := range strings.Split(md, "\n") {
+		if strings.HasPrefix(line, "#") {
+			return strings.TrimSpace(strings.TrimLeft(line, "#"))
+		}
+	}
+	return u.Path
+}
+
+// writeIndex 在站点目录下生成 INDEX.md，列出本次抓取保存的所有页面。
+func (c *crawler) writeIndex() error {
+	pages := append([]crawledPage(nil), c.pages...)
+	sort.Slice(pages, func(i, j int) bool { return pages[i].file < pages[j].file })
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", c.start.Host)
+	fmt.Fprintf(&b, "抓取自 %s（%s）\n\n", c.start, time.Now().Format("2006-01-02 15:04"))
+	for _, p := range pages {
+		fmt.Fprintf(&b, "- [%s](%s) — %s\n", p.title, p.file, p.url)
+	}
+	return writeFileAtomic(filepath.Join(c.outDir, "INDEX.md"), []byte(b.String()), defaultFileMode)
+}
+
+func (c *crawler) summary(dir string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "已抓取 %d 个页面，保存到 %s/\n", len(c.pages), dir)
+	if c.robotsDenied+c.nonHTML+c.failed > 0 {
+		fmt.Fprintf(&b, "跳过：robots.txt 禁止 %d 个，非 HTML %d 个，失败 %d 个\n", c.robotsDenied, c.nonHTML, c.failed)
+	}
+	if c.stopped != "" {
+		fmt.Fprintf(&b, "（%s，其余页面未抓取）\n", c.stopped)
+	}
+	if len(c.pages) == 0 {
+		return strings.TrimSpace(b.String())
+	}
+	fmt.Fprintf(&b, "\n搜索这些文档时请给 grep / glob 显式传入 path=%s（它位于隐藏目录下，只有搜索整个工作目录时才会自动包含），或用 read_file 打开：\n", dir)
+	fmt.Fprintf(&b, "  %s/INDEX.md\n", dir)
+	for i, p := range c.pages {
+		if i == crawlOutputFileLimit {
+			fmt.Fprintf(&b, "  … 另有 %d 个页面，见 INDEX.md\n", len(c.pages)-i)
+			break
+		}
+		fmt.Fprintf(&b, "  %s/%s\n", dir, p.file)
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,178 @@
+package tool
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/afumu/openlink/internal/types"
+)
+
+func TestParseRobots(t *testing.T) {
+	robots := `
+# comment
+User-agent: Googlebot
+Disallow: /
+
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Crawl-delay: 2
+
+User-agent: openlink-crawler
+User-agent: other
+Disallow: /internal/
+`
+	r := parseRobots(robots, "Mozilla/5.0")
+	cases := map[string]bool{
+		"/":                   true,
+		"/private":            false,
+		"/private/x":          false,
+		"/private/public/doc": true,
+		"/guide.pdf":          false,
+		"/guide.pdf?x=1":      true,
+	}
+	for p, want := range cases {
+		if got := r.Allowed(p); got != want {
+			t.Errorf("Allowed(%q) = %v, want %v", p, got, want)
+		}
+	}
+	if r.crawlDelay != 2*time.Second {
+		t.Errorf("crawl delay = %v", r.crawlDelay)
+	}
+
+	own := parseRobots(robots, crawlUserAgent)
+	if own.Allowed("/internal/a") || !own.Allowed("/private") {
+		t.Error("expected the openlink-crawler group to be selected")
+	}
+	if !parseRobots("", crawlUserAgent).Allowed("/anything") {
+		t.Error("empty robots.txt should allow everything")
+	}
+}
+
+func TestWebCrawl(t *testing.T) {
+	var requests []string
+	pages := map[string]string{
+		"/docs/": `<a href="a.html">A</a> <a href="b/">B</a> <a href="/docs/private/x">P</a>
+			<a href="/blog/post">Blog</a> <a href="http://other.example/docs/">Ext</a> <a href="logo.png">Logo</a>
+			<a href="#top">Top</a>`,
+		"/docs/a.html":      `<a href="deep.html#s1">Deep</a>`,
+		"/docs/b/":          `<p>section b</p>`,
+		"/docs/deep.html":   `<p>needle in deep page</p><a href="deeper.html">Deeper</a>`,
+		"/docs/deeper.html": `<p>too deep</p>`,
+		"/docs/private/x":   `<p>private</p>`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, "User-agent: *\nDisallow: /docs/private\n")
+			return
+		}
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("User-Agent") != crawlUserAgent {
+			t.Errorf("unexpected user agent %q", r.Header.Get("User-Agent"))
+		}
+		title := strings.Trim(r.URL.Path, "/")
+		fmt.Fprintf(w, "<html><head><title>%s</title></head><body><main><h1>%s</h1>%s</main></body></html>", title, title, body)
+	}))
+	defer srv.Close()
+
+	cfg := &types.Config{RootDir: t.TempDir(), Timeout: 30}
+	crawl := &WebCrawlTool{config: cfg, policy: func(ip net.IP, port int) bool { return ip.IsLoopback() }}
+	args := map[string]interface{}{"url": srv.URL + "/docs/", "depth": "2", "delay_ms": "0"}
+	if err := crawl.Validate(args); err != nil {
+		t.Fatal(err)
+	}
+	res := crawl.Execute(&Context{Args: args, Config: cfg})
+	if res.Status != "success" {
+		t.Fatal(res.Error)
+	}
+
+	site := filepath.Join(cfg.RootDir, ".openlink", "docs", strings.ReplaceAll(srv.Listener.Addr().String(), ":", "_"))
+	for _, f := range []string{"docs/index.md", "docs/a.md", "docs/b/index.md", "docs/deep.md", "INDEX.md"} {
+		if _, err := os.Stat(filepath.Join(site, f)); err != nil {
+			t.Errorf("expected %s to be saved: %v", f, err)
+		}
+	}
+	for _, f := range []string{"docs/deeper.md", "docs/private/x.md"} {
+		if _, err := os.Stat(filepath.Join(site, f)); err == nil {
+			t.Errorf("%s should not have been saved", f)
+		}
+	}
+	for _, p := range requests {
+		if p == "/blog/post" || p == "/docs/private/x" || p == "/docs/logo.png" {
+			t.Errorf("should not have requested %s", p)
+		}
+	}
+	if !strings.Contains(res.Output, "已抓取 4 个页面") || !strings.Contains(res.Output, "robots.txt 禁止 1 个") {
+		t.Errorf("unexpected summary:\n%s", res.Output)
+	}
+	if data, _ := os.ReadFile(filepath.Join(site, "docs", "deep.md")); !strings.HasPrefix(string(data), "<!-- source: "+srv.URL+"/docs/deep.html -->") {
+		t.Errorf("missing source header: %q", data)
+	}
+
+	// 抓取结果可直接被 grep 搜索
+	g := NewGrepTool(cfg).Execute(testCtx(cfg, map[string]interface{}{"pattern": "needle", "path": ".openlink/docs"}))
+	if !strings.Contains(g.Output, "deep.md") {
+		t.Errorf("expected grep to find crawled page, got %q", g.Output)
+	}
+	if !strings.Contains(res.Output, "path=.openlink/docs/") {
+		t.Errorf("summary should tell the model which path to search:\n%s", res.Output)
+	}
+
+	// 搜索整个工作目录时也包含抓取的文档，但 .openlink 下的其他隐藏文件仍被跳过
+	os.WriteFile(filepath.Join(cfg.RootDir, ".openlink", "notes.md"), []byte("needle\n"), 0644)
+	g = NewGrepTool(cfg).Execute(testCtx(cfg, map[string]interface{}{"pattern": "needle"}))
+	if !strings.Contains(g.Output, ".openlink/docs/") || strings.Contains(g.Output, "notes.md") {
+		t.Errorf("expected workspace grep to include only the crawled docs, got %q", g.Output)
+	}
+	gl := NewGlobTool(cfg).Execute(testCtx(cfg, map[string]interface{}{"pattern": "**/deep.md"}))
+	if !strings.Contains(gl.Output, ".openlink/docs/") {
+		t.Errorf("expected workspace glob to include the crawled docs, got %q", gl.Output)
+	}
+	if gl := NewGlobTool(cfg).Execute(testCtx(cfg, map[string]interface{}{"pattern": "*.md"})); strings.Contains(gl.Output, "notes.md") {
+		t.Errorf("other hidden files should stay hidden, got %q", gl.Output)
+	}
+
+	// max_pages 限制
+	cfg2 := &types.Config{RootDir: t.TempDir()}
+	crawl.config = cfg2
+	res = crawl.Execute(&Context{Args: map[string]interface{}{"url": srv.URL + "/docs/", "max_pages": "2", "delay_ms": "0"}, Config: cfg2})
+	if !strings.Contains(res.Output, "已抓取 2 个页面") || !strings.Contains(res.Output, "max_pages=2") {
+		t.Errorf("expected page budget to stop crawl, got:\n%s", res.Output)
+	}
+}
+
+func TestCrawlDocFile(t *testing.T) {
+	c := &crawler{files: map[string]bool{}}
+	cases := []struct{ url, want string }{
+		{"https://x.dev/", "index.md"},
+		{"https://x.dev/guide/", "guide/index.md"},
+		{"https://x.dev/api/net.html", "api/net.md"},
+		{"https://x.dev/api/net", "api/net_2.md"},
+		{"https://x.dev/search?q=go", "search_"},
+	}
+	for _, tc := range cases {
+		u, _ := http.NewRequest("GET", tc.url, nil)
+		if got := c.docFile(u.URL); got != tc.want && !(strings.HasSuffix(tc.want, "_") && strings.HasPrefix(got, tc.want)) {
+			t.Errorf("docFile(%s) = %s, want %s", tc.url, got, tc.want)
+		}
+	}
+	if p := crawlPrefix("/docs/intro.html"); p != "/docs/" {
+		t.Errorf("crawlPrefix = %s", p)
+	}
+	if p := crawlPrefix(""); p != "/" {
+		t.Errorf("crawlPrefix(\"\") = %s", p)
+	}
+}
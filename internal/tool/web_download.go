@@ -0,0 +1,117 @@
+package tool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/afumu/openlink/internal/security"
+)
+
+// defaultMaxDownloadBytes 是 web_fetch save_to 的默认大小上限，可由项目配置 max_download_bytes 调整。
+const defaultMaxDownloadBytes int64 = 100 * 1024 * 1024
+
+// downloadLimit 返回本次下载的大小上限：max_bytes 参数只能收紧项目配置的上限。
+func downloadLimit(ctx *Context) int64 {
+	limit := defaultMaxDownloadBytes
+	if ctx.Config != nil && ctx.Config.Project.MaxDownloadBytes > 0 {
+		limit = ctx.Config.Project.MaxDownloadBytes
+	}
+	if v, ok := intArg(ctx.Args, "max_bytes"); ok && v > 0 && int64(v) < limit {
+		limit = int64(v)
+	}
+	return limit
+}
+
+// validSHA256 判断 s 是否为 64 位十六进制的 SHA-256 摘要。
+func validSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// saveToPath 把 save_to 解析为工作目录内的绝对路径，越出工作目录或指向项目配置时报错。
+func saveToPath(rootDir, saveTo string) (string, error) {
+	var path string
+	var err error
+	if filepath.IsAbs(saveTo) {
+		path, err = security.SafeAbsPath(saveTo, rootDir)
+	} else {
+		path, err = security.SafePath(rootDir, saveTo)
+	}
+	if err != nil {
+		return "", err
+	}
+	return path, checkWritable(rootDir, path)
+}
+
+// saveResponse 将响应体流式写入工作目录内的 saveTo，边写边计算 SHA-256。
+// 超过大小上限或摘要与 expected 不符时放弃写入，已有文件保持不变。
+func saveResponse(ctx *Context, resp *http.Response, saveTo string) (string, error) {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("download failed: %s", resp.Status)
+	}
+
+	path, err := saveToPath(ctx.Config.RootDir, saveTo)
+	if err != nil {
+		return "", err
+	}
+	if err := checkFresh(ctx, path); err != nil {
+		return "", err
+	}
+
+	limit := downloadLimit(ctx)
+	if resp.ContentLength > limit {
+		return "", fmt.Errorf("response is %s, exceeds the %s download limit", formatSize(resp.ContentLength), formatSize(limit))
+	}
+	expected, _ := ctx.Args["sha256"].(string)
+	expected = strings.ToLower(strings.TrimSpace(expected))
+
+	h := sha256.New()
+	var size int64
+	err = writeAtomic(path, defaultFileMode, func(w io.Writer) error {
+		n, err := io.Copy(io.MultiWriter(w, h), io.LimitReader(resp.Body, limit+1))
+		size = n
+		if err != nil {
+			return err
+		}
+		if n > limit {
+			return fmt.Errorf("response exceeds the %s download limit", formatSize(limit))
+		}
+		if expected != "" && hex.EncodeToString(h.Sum(nil)) != expected {
+			return fmt.Errorf("sha256 mismatch: expected %s, got %s", expected, hex.EncodeToString(h.Sum(nil)))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	if ctx.Files != nil {
+		if info, err := os.Stat(path); err == nil {
+			ctx.Files.Record(ctx.Session, path, sum[:16], info.ModTime())
+		}
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "unknown"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "已保存到 %s\n", displayPath(workspaceRoot(ctx.Config.RootDir), path))
+	fmt.Fprintf(&b, "url: %s\n", security.RedactURL(resp.Request.URL.String()))
+	fmt.Fprintf(&b, "content-type: %s\n", contentType)
+	fmt.Fprintf(&b, "size: %s (%d bytes)\n", formatSize(size), size)
+	fmt.Fprintf(&b, "sha256: %s", sum)
+	if expected != "" {
+		b.WriteString(" (verified)")
+	}
+	return b.String(), nil
+}
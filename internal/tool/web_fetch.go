@@ -1,45 +1,86 @@
 package tool
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strings"
 	"time"
+
+	"github.com/afumu/openlink/internal/security"
+	"github.com/afumu/openlink/internal/types"
+	"github.com/afumu/openlink/internal/webcache"
 )
 
-type WebFetchTool struct{}
+type WebFetchTool struct {
+	config *types.Config
+	// policy 决定允许连接的地址，nil 表示只允许公网地址（测试中可放行本地监听端口）
+	policy security.IPPolicy
+	// cache 为 nil 时不使用本地缓存
+	cache *webcache.Cache
+}
 
-func NewWebFetchTool() *WebFetchTool { return &WebFetchTool{} }
+func NewWebFetchTool(config *types.Config) *WebFetchTool {
+	t := &WebFetchTool{config: config}
+	if dir, err := webcache.DefaultDir(); err == nil {
+		t.cache = webcache.New(dir)
+		t.cache.Offline = config.Offline
+	}
+	return t
+}
 
 func (t *WebFetchTool) Name() string        { return "web_fetch" }
 func (t *WebFetchTool) Description() string { return "Fetch web page content via HTTP" }
 func (t *WebFetchTool) Parameters() interface{} {
 	return map[string]string{
-		"url":    "string (required) - http/https URL to fetch",
-		"format": "string (optional) - 'text' (default, strips HTML) or 'html'",
+		"url":       "string (required) - http/https URL to fetch",
+		"format":    "string (optional) - 'markdown' (default, main content as Markdown), 'text' (main content as plain text) or 'html' (raw)",
+		"save_to":   "string (optional) - save the raw response body to this workspace path instead of returning it",
+		"max_bytes": "number (optional) - size limit for save_to downloads (default and maximum: project max_download_bytes, 100 MiB)",
+		"sha256":    "string (optional) - expected SHA-256 of the download; the file is not saved on mismatch",
 	}
 }
 
-func isPrivateIP(ip net.IP) bool {
-	private := []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "127.0.0.0/8", "169.254.0.0/16", "::1/128", "fc00::/7"}
-	for _, cidr := range private {
-		_, block, _ := net.ParseCIDR(cidr)
-		if block.Contains(ip) {
-			return true
-		}
-	}
-	return false
-}
-
 func (t *WebFetchTool) Validate(args map[string]interface{}) error {
 	rawURL, ok := args["url"].(string)
 	if !ok || rawURL == "" {
 		return fmt.Errorf("url is required")
 	}
+	switch f, _ := args["format"].(string); f {
+	case "", "markdown", "text", "html":
+	default:
+		return fmt.Errorf("format must be 'markdown', 'text' or 'html'")
+	}
+	if sum, _ := args["sha256"].(string); sum != "" && !validSHA256(strings.TrimSpace(sum)) {
+		return fmt.Errorf("sha256 must be a 64-character hex digest")
+	}
+	if err := checkOffline(t.config, t.cache); err != nil {
+		return err
+	}
+	// 保存路径在联网前校验，越出工作目录的请求不会发出
+	if saveTo, _ := args["save_to"].(string); saveTo != "" && t.config != nil {
+		if _, err := saveToPath(t.config.RootDir, saveTo); err != nil {
+			return err
+		}
+	}
+	return checkFetchURL(rawURL, t.policy, t.cache != nil && t.cache.Offline)
+}
+
+// checkOffline 在离线模式下无法使用本地缓存时报错，避免静默改为联网。
+func checkOffline(config *types.Config, cache *webcache.Cache) error {
+	if config != nil && config.Offline && cache == nil {
+		return errors.New("offline mode is enabled but the local web cache is unavailable")
+	}
+	return nil
+}
+
+// checkFetchURL 校验对外抓取的 URL：只允许 http/https，并提前解析一次主机以便尽早报错；
+// 真正的校验在拨号时针对实际连接的 IP 进行。离线模式不会联网，跳过解析。
+func checkFetchURL(rawURL string, policy security.IPPolicy, offline bool) error {
 	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
 		return fmt.Errorf("only http/https URLs are supported")
 	}
@@ -47,38 +88,40 @@ func (t *WebFetchTool) Validate(args map[string]interface{}) error {
 	if err != nil {
 		return fmt.Errorf("invalid URL")
 	}
-	host := parsed.Hostname()
-	ips, err := net.LookupHost(host)
+	if offline {
+		return nil
+	}
+	host, port := urlHostPort(parsed)
+	ips, err := net.LookupIP(host)
 	if err != nil {
 		return fmt.Errorf("cannot resolve host: %s", host)
 	}
-	for _, ipStr := range ips {
-		if ip := net.ParseIP(ipStr); ip != nil && isPrivateIP(ip) {
-			return fmt.Errorf("requests to private/internal addresses are not allowed")
+	if policy == nil {
+		policy = security.PublicOnly
+	}
+	for _, ip := range ips {
+		if !policy(ip, port) {
+			return security.ErrBlockedAddress
 		}
 	}
 	return nil
 }
 
-var (
-	htmlTagRe    = regexp.MustCompile(`<[^>]+>`)
-	multiSpaceRe = regexp.MustCompile(`[ \t]{2,}`)
-	multiNewline = regexp.MustCompile(`\n{3,}`)
-)
-
-func stripHTML(s string) string {
-	s = htmlTagRe.ReplaceAllString(s, " ")
-	s = multiSpaceRe.ReplaceAllString(s, " ")
-	s = multiNewline.ReplaceAllString(s, "\n\n")
-	return strings.TrimSpace(s)
-}
-
 func (t *WebFetchTool) Execute(ctx *Context) *Result {
 	result := &Result{StartTime: time.Now()}
 	url, _ := ctx.Args["url"].(string)
 	format, _ := ctx.Args["format"].(string)
+	saveTo, _ := ctx.Args["save_to"].(string)
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	timeout := 30 * time.Second
+	if saveTo != "" {
+		timeout = 10 * time.Minute
+	}
+	client := security.NewGuardedClient(timeout, t.policy)
+	// 下载文件不进缓存；离线模式下下载同样只能来自缓存
+	if t.cache != nil && (saveTo == "" || t.cache.Offline) {
+		client.Transport = t.cache.Transport(client.Transport)
+	}
 	resp, err := client.Get(url)
 	if err != nil {
 		result.Status = "error"
@@ -87,6 +130,19 @@ func (t *WebFetchTool) Execute(ctx *Context) *Result {
 	}
 	defer resp.Body.Close()
 
+	if saveTo != "" {
+		output, err := saveResponse(ctx, resp, saveTo)
+		if err != nil {
+			result.Status = "error"
+			result.Error = err.Error()
+			return result
+		}
+		result.Status = "success"
+		result.Output = output
+		result.EndTime = time.Now()
+		return result
+	}
+
 	body, err := io.ReadAll(io.LimitReader(resp.Body, 1*1024*1024))
 	if err != nil {
 		result.Status = "error"
@@ -94,14 +150,30 @@ func (t *WebFetchTool) Execute(ctx *Context) *Result {
 		return result
 	}
 
+	contentType := resp.Header.Get("Content-Type")
 	content := string(body)
-	if format != "html" {
-		content = stripHTML(content)
+	if isHTMLContent(contentType, body) {
+		content = decodeHTMLBody(body, contentType)
+		if format != "html" {
+			content = pageToMarkdown(content, resp.Request.URL, format == "text")
+		}
 	}
 
+	if resp.Header.Get(webcache.Header) == "offline" {
+		content += "\n\n(离线模式：内容来自本地缓存，可能已过期)"
+	}
 	output, _ := Truncate(content)
 	result.Status = "success"
 	result.Output = output
 	result.EndTime = time.Now()
 	return result
 }
+
+// isHTMLContent 判断响应是否为 HTML；非 HTML（JSON、纯文本等）原样返回。
+func isHTMLContent(contentType string, body []byte) bool {
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
@@ -1,14 +1,25 @@
 package tool
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/afumu/openlink/internal/security"
+	"github.com/afumu/openlink/internal/types"
+	"github.com/afumu/openlink/internal/webcache"
+	"golang.org/x/text/encoding/simplifiedchinese"
 )
 
 func TestWebFetchValidate(t *testing.T) {
-	tool := NewWebFetchTool()
+	tool := NewWebFetchTool(&types.Config{})
 
 	if err := tool.Validate(map[string]interface{}{}); err == nil {
 		t.Error("expected error for missing url")
@@ -19,7 +30,7 @@ func TestWebFetchValidate(t *testing.T) {
 }
 
 func TestWebFetchSSRFBlocked(t *testing.T) {
-	tool := NewWebFetchTool()
+	tool := NewWebFetchTool(&types.Config{})
 
 	blocked := []string{
 		"http://127.0.0.1/",
@@ -34,13 +45,18 @@ func TestWebFetchSSRFBlocked(t *testing.T) {
 	}
 }
 
+// loopbackFetchTool 返回允许访问本机监听端口的 web_fetch，用于针对 httptest 服务器测试。
+func loopbackFetchTool() *WebFetchTool {
+	return &WebFetchTool{policy: func(ip net.IP, port int) bool { return ip.IsLoopback() }}
+}
+
 func TestWebFetchExecute(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("<html><body>hello world</body></html>"))
 	}))
 	defer srv.Close()
 
-	tool := NewWebFetchTool()
+	tool := loopbackFetchTool()
 	res := tool.Execute(&Context{Args: map[string]interface{}{"url": srv.URL}})
 	if res.Status != "success" {
 		t.Fatalf("expected success: %s", res.Error)
@@ -49,3 +65,230 @@ func TestWebFetchExecute(t *testing.T) {
 		t.Errorf("expected content in output, got %q", res.Output)
 	}
 }
+
+func TestWebFetchMarkdown(t *testing.T) {
+	page := `<html><head><title>Guide</title><script>var tracking = 1;</script><style>.x{}</style></head>
+<body>
+<nav><a href="/">Home</a> <a href="/docs">Docs</a></nav>
+<div class="cookie-banner">We use cookies. <button>Accept</button></div>
+<main>
+<h1>Getting started</h1>
+<p>Install the <strong>tool</strong> and read the <a href="/docs/install">install guide</a>, which covers every platform in detail.</p>
+<ul><li>fast</li><li>simple<ul><li>nested</li></ul></li></ul>
+<pre><code class="language-go">fmt.Println("hi")</code></pre>
+<table><tr><th>Name</th><th>Value</th></tr><tr><td>a</td><td>1</td></tr></table>
+</main>
+<footer>Copyright 2026</footer>
+</body></html>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	res := loopbackFetchTool().Execute(&Context{Args: map[string]interface{}{"url": srv.URL + "/guide"}})
+	if res.Status != "success" {
+		t.Fatalf("expected success: %s", res.Error)
+	}
+	for _, want := range []string{
+		"# Getting started",
+		"**tool**",
+		"[install guide](" + srv.URL + "/docs/install)",
+		"- fast\n- simple\n  - nested",
+		"```go\nfmt.Println(\"hi\")\n```",
+		"| Name | Value |\n| --- | --- |\n| a | 1 |",
+	} {
+		if !strings.Contains(res.Output, want) {
+			t.Errorf("expected %q in output:\n%s", want, res.Output)
+		}
+	}
+	for _, unwanted := range []string{"tracking", "cookies", "Copyright", "Home"} {
+		if strings.Contains(res.Output, unwanted) {
+			t.Errorf("boilerplate %q should be dropped:\n%s", unwanted, res.Output)
+		}
+	}
+}
+
+func TestPageToMarkdownFormWrapped(t *testing.T) {
+	page := `<html><body>
+<form action="/login"><label>Sign in to your account</label><input name="user"><button>Login</button></form>
+<form id="aspnetForm" method="post" action="./Default.aspx">
+<input type="hidden" name="__VIEWSTATE" value="abc">
+<div id="content"><h1>Release notes</h1>
+<p>Version 4.2 adds streaming uploads and fixes several long-standing bugs in the scheduler.</p>
+<p>Upgrading from 4.1 requires no configuration changes; restart the service after installing.</p></div>
+</form></body></html>`
+	out := pageToMarkdown(page, nil, false)
+	for _, want := range []string{"# Release notes", "streaming uploads", "restart the service"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Sign in") {
+		t.Errorf("small login form should be dropped:\n%s", out)
+	}
+}
+
+func TestWebFetchCharset(t *testing.T) {
+	gbk, _ := simplifiedchinese.GBK.NewEncoder().String("<html><body><p>你好，世界</p></body></html>")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=gbk")
+		w.Write([]byte(gbk))
+	}))
+	defer srv.Close()
+
+	for _, format := range []string{"markdown", "html"} {
+		res := loopbackFetchTool().Execute(&Context{Args: map[string]interface{}{"url": srv.URL, "format": format}})
+		if !strings.Contains(res.Output, "你好，世界") {
+			t.Errorf("%s: expected decoded GBK text, got %q", format, res.Output)
+		}
+	}
+}
+
+func TestWebFetchDialTimeGuard(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal secret"))
+	}))
+	defer internal.Close()
+
+	// 默认策略下即使跳过 Validate，拨号阶段也会拒绝本机地址
+	res := (&WebFetchTool{}).Execute(&Context{Args: map[string]interface{}{"url": internal.URL}})
+	if res.Status != "error" || strings.Contains(res.Output, "secret") {
+		t.Fatalf("expected dial-time block, got %s %q", res.Status, res.Output)
+	}
+
+	// 只放行"公网"服务器的端口，它重定向到内部服务
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL+"/exec", http.StatusFound)
+	}))
+	defer public.Close()
+	publicPort := public.Listener.Addr().(*net.TCPAddr).Port
+	tool := &WebFetchTool{policy: func(ip net.IP, port int) bool { return port == publicPort }}
+	if err := tool.Validate(map[string]interface{}{"url": public.URL}); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	res = tool.Execute(&Context{Args: map[string]interface{}{"url": public.URL}})
+	if res.Status != "error" || !strings.Contains(res.Error, security.ErrBlockedAddress.Error()) {
+		t.Errorf("expected redirect to internal address to be blocked, got %s %q", res.Status, res.Error)
+	}
+	if err := tool.Validate(map[string]interface{}{"url": internal.URL}); !errors.Is(err, security.ErrBlockedAddress) {
+		t.Errorf("expected ErrBlockedAddress from Validate, got %v", err)
+	}
+}
+
+func TestWebFetchSaveTo(t *testing.T) {
+	payload := []byte("\x89PNG\r\n\x1a\n" + strings.Repeat("x", 4096))
+	sum := sha256.Sum256(payload)
+	digest := hex.EncodeToString(sum[:])
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(payload)
+	}))
+	defer srv.Close()
+
+	cfg := &types.Config{RootDir: t.TempDir()}
+	tool := loopbackFetchTool()
+	run := func(args map[string]interface{}) *Result {
+		t.Helper()
+		if err := tool.Validate(args); err != nil {
+			return &Result{Status: "error", Error: err.Error()}
+		}
+		return tool.Execute(&Context{Args: args, Config: cfg})
+	}
+
+	res := run(map[string]interface{}{"url": srv.URL + "/logo.png", "save_to": "assets/logo.png", "sha256": strings.ToUpper(digest)})
+	if res.Status != "success" {
+		t.Fatal(res.Error)
+	}
+	for _, want := range []string{"assets/logo.png", "image/png", "4104 bytes", digest, "(verified)"} {
+		if !strings.Contains(res.Output, want) {
+			t.Errorf("missing %q in %q", want, res.Output)
+		}
+	}
+	if got, _ := os.ReadFile(filepath.Join(cfg.RootDir, "assets", "logo.png")); string(got) != string(payload) {
+		t.Error("saved file does not match response body")
+	}
+
+	bad := map[string]map[string]interface{}{
+		"hash mismatch": {"url": srv.URL, "save_to": "a.png", "sha256": strings.Repeat("0", 64)},
+		"too large":     {"url": srv.URL, "save_to": "b.png", "max_bytes": "1024"},
+		"http error":    {"url": srv.URL + "/missing", "save_to": "c.png"},
+		"outside root":  {"url": srv.URL, "save_to": "../d.png"},
+		"bad digest":    {"url": srv.URL, "save_to": "e.png", "sha256": "abc"},
+	}
+	for name, args := range bad {
+		res := run(args)
+		if res.Status != "error" {
+			t.Errorf("%s: expected error, got %q", name, res.Output)
+		}
+		if _, err := os.Stat(filepath.Join(cfg.RootDir, args["save_to"].(string))); err == nil {
+			t.Errorf("%s: file should not have been written", name)
+		}
+	}
+}
+
+func TestWebFetchValidateSaveTo(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { requests++ }))
+	defer srv.Close()
+
+	tool := loopbackFetchTool()
+	tool.config = &types.Config{RootDir: t.TempDir()}
+	for _, saveTo := range []string{"../escape.bin", "/etc/escape.bin"} {
+		args := map[string]interface{}{"url": srv.URL, "save_to": saveTo}
+		if err := tool.Validate(args); err == nil {
+			t.Errorf("%s: expected Validate to reject path outside root", saveTo)
+		}
+	}
+	if err := tool.Validate(map[string]interface{}{"url": srv.URL, "save_to": "ok.bin"}); err != nil {
+		t.Errorf("expected workspace path to pass, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("Validate should not send requests, got %d", requests)
+	}
+}
+
+func TestWebFetchOfflineWithoutCache(t *testing.T) {
+	cfg := &types.Config{RootDir: t.TempDir(), Offline: true}
+	fetch := loopbackFetchTool()
+	fetch.config = cfg
+	if err := fetch.Validate(map[string]interface{}{"url": "https://example.com/"}); err == nil || !strings.Contains(err.Error(), "offline") {
+		t.Errorf("web_fetch should refuse to go online in offline mode, got %v", err)
+	}
+	crawl := &WebCrawlTool{config: cfg}
+	if err := crawl.Validate(map[string]interface{}{"url": "https://example.com/"}); err == nil || !strings.Contains(err.Error(), "offline") {
+		t.Errorf("web_crawl should refuse to go online in offline mode, got %v", err)
+	}
+}
+
+func TestWebFetchOfflineReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body><main><h1>Recorded</h1><p>cached docs page</p></main></body></html>"))
+	}))
+	cache := webcache.New(t.TempDir())
+	tool := loopbackFetchTool()
+	tool.cache = cache
+	args := map[string]interface{}{"url": srv.URL + "/docs"}
+	if res := tool.Execute(&Context{Args: args}); res.Status != "success" {
+		t.Fatal(res.Error)
+	}
+	srv.Close()
+
+	cache.Offline = true
+	if err := tool.Validate(args); err != nil {
+		t.Fatalf("offline validate should not resolve hosts: %v", err)
+	}
+	res := tool.Execute(&Context{Args: args})
+	if res.Status != "success" || !strings.Contains(res.Output, "# Recorded") || !strings.Contains(res.Output, "离线模式") {
+		t.Errorf("expected replay from cache, got %s %q", res.Error, res.Output)
+	}
+	res = tool.Execute(&Context{Args: map[string]interface{}{"url": srv.URL + "/other"}})
+	if res.Status != "error" || !strings.Contains(res.Error, "not in web cache") {
+		t.Errorf("expected cache miss error, got %s %q", res.Status, res.Error)
+	}
+}
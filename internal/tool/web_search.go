@@ -0,0 +1,175 @@
+package tool
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/afumu/openlink/internal/search"
+	"github.com/afumu/openlink/internal/security"
+	"github.com/afumu/openlink/internal/types"
+)
+
+const (
+	defaultSearchLimit = 8
+	maxSearchLimit     = 20
+	maxSnippetRunes    = 300
+)
+
+var tagRe = regexp.MustCompile(`<[^>]*>`)
+
+// internalSuffixes 是只在内网解析的主机名后缀，这类搜索结果会被丢弃。
+var internalSuffixes = []string{".localhost", ".local", ".internal", ".lan", ".home.arpa", ".corp"}
+
+// WebSearchTool 通过 settings.json 中配置的后端搜索网页，返回归一化的标题、链接与摘要。
+type WebSearchTool struct {
+	backend search.Backend
+	err     error
+	offline bool
+}
+
+func NewWebSearchTool(config *types.Config) *WebSearchTool {
+	backend, err := search.New(config.Search, &http.Client{Timeout: 20 * time.Second})
+	return &WebSearchTool{backend: backend, err: err, offline: config.Offline}
+}
+
+func (t *WebSearchTool) Name() string { return "web_search" }
+func (t *WebSearchTool) Description() string {
+	return "Search the web and return titles, URLs and snippets"
+}
+func (t *WebSearchTool) Parameters() interface{} {
+	return map[string]string{
+		"query": "string (required) - search query",
+		"limit": "number (optional) - max results (default 8, max 20)",
+		"site":  "string (optional) - restrict results to this domain, e.g. go.dev",
+	}
+}
+
+func (t *WebSearchTool) Validate(args map[string]interface{}) error {
+	if q, _ := args["query"].(string); strings.TrimSpace(q) == "" {
+		return errors.New("query is required")
+	}
+	if t.err != nil {
+		return t.err
+	}
+	if t.offline {
+		return errors.New("web_search is unavailable in offline mode")
+	}
+	return nil
+}
+
+func (t *WebSearchTool) Execute(ctx *Context) *Result {
+	result := &Result{StartTime: time.Now()}
+	query, _ := ctx.Args["query"].(string)
+	query = strings.TrimSpace(query)
+	site, _ := ctx.Args["site"].(string)
+	if site = strings.TrimSpace(site); site != "" {
+		query += " site:" + site
+	}
+	limit := defaultSearchLimit
+	if v, ok := intArg(ctx.Args, "limit"); ok && v >= 1 {
+		limit = min(v, maxSearchLimit)
+	}
+
+	c, cancel := ctx.WithToolTimeout()
+	defer cancel()
+	// 多取一些，弥补过滤掉的结果
+	raw, err := t.backend.Search(c, query, limit*2)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+
+	results := filterSearchResults(raw, limit)
+	result.Status = "success"
+	result.Output = formatSearchResults(query, results)
+	result.EndTime = time.Now()
+	return result
+}
+
+// filterSearchResults 按与 web_fetch 相同的规则过滤结果：只保留指向公网 http/https 地址的链接，
+// 去掉链接中的凭据，清理标题和摘要中的 HTML，并按 URL 去重。
+func filterSearchResults(raw []search.Result, limit int) []search.Result {
+	seen := map[string]bool{}
+	var out []search.Result
+	for _, r := range raw {
+		u, err := url.Parse(strings.TrimSpace(r.URL))
+		if err != nil || !publicResultURL(u) {
+			continue
+		}
+		link := security.RedactURL(u.String())
+		if seen[link] {
+			continue
+		}
+		seen[link] = true
+		out = append(out, search.Result{
+			Title:   cleanSearchText(r.Title, 0),
+			URL:     link,
+			Snippet: cleanSearchText(r.Snippet, maxSnippetRunes),
+		})
+		if len(out) == limit {
+			break
+		}
+	}
+	return out
+}
+
+// publicResultURL 判断搜索结果链接是否指向公网：字面 IP 须为公网地址，主机名不能是内网专用名称。
+// 结果只是被展示而不会被请求，真正抓取时 web_fetch 还会在拨号阶段再次校验。
+func publicResultURL(u *url.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" || host == "localhost" || (!strings.Contains(host, ".") && net.ParseIP(host) == nil) {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return security.IsPublicIP(ip)
+	}
+	for _, suffix := range internalSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+func cleanSearchText(s string, maxRunes int) string {
+	s = html.UnescapeString(tagRe.ReplaceAllString(s, ""))
+	s = strings.TrimSpace(spaceRe.ReplaceAllString(s, " "))
+	if maxRunes > 0 {
+		if r := []rune(s); len(r) > maxRunes {
+			s = string(r[:maxRunes]) + "…"
+		}
+	}
+	return s
+}
+
+func formatSearchResults(query string, results []search.Result) string {
+	if len(results) == 0 {
+		return fmt.Sprintf("没有找到与 %q 相关的结果", query)
+	}
+	var b strings.Builder
+	for i, r := range results {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		title := r.Title
+		if title == "" {
+			title = r.URL
+		}
+		fmt.Fprintf(&b, "%d. %s\n   %s\n", i+1, title, r.URL)
+		if r.Snippet != "" {
+			fmt.Fprintf(&b, "   %s\n", r.Snippet)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,67 @@
+package tool
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/afumu/openlink/internal/search"
+	"github.com/afumu/openlink/internal/types"
+)
+
+func TestWebSearchNotConfigured(t *testing.T) {
+	tool := NewWebSearchTool(&types.Config{})
+	if err := tool.Validate(map[string]interface{}{"query": "go"}); err == nil || !strings.Contains(err.Error(), "settings.json") {
+		t.Errorf("expected not-configured error, got %v", err)
+	}
+}
+
+func TestWebSearchExecute(t *testing.T) {
+	stub := &search.Stub{Results: []search.Result{
+		{Title: "<b>Go</b> &amp; generics", URL: "https://go.dev/doc/", Snippet: "A   <em>tutorial</em>\n on generics"},
+		{Title: "dup", URL: "https://go.dev/doc/"},
+		{Title: "metadata", URL: "http://169.254.169.254/latest/"},
+		{Title: "local", URL: "http://localhost:8080/"},
+		{Title: "intranet", URL: "https://wiki.corp/page"},
+		{Title: "single label", URL: "http://router/"},
+		{Title: "file", URL: "file:///etc/passwd"},
+		{Title: "creds", URL: "https://user:pw@example.com/x?token=abc&page=2"},
+	}}
+	tool := &WebSearchTool{backend: stub}
+	args := map[string]interface{}{"query": "generics", "site": "go.dev", "limit": "5"}
+	if err := tool.Validate(args); err != nil {
+		t.Fatal(err)
+	}
+	res := tool.Execute(&Context{Args: args})
+	if res.Status != "success" {
+		t.Fatal(res.Error)
+	}
+	if stub.Queries[0] != "generics site:go.dev" {
+		t.Errorf("unexpected query %q", stub.Queries[0])
+	}
+	want := "1. Go & generics\n   https://go.dev/doc/\n   A tutorial on generics\n\n" +
+		"2. creds\n   https://example.com/x?page=2&token=REDACTED"
+	if res.Output != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", res.Output, want)
+	}
+
+	stub.Results = nil
+	if res := tool.Execute(&Context{Args: args}); !strings.Contains(res.Output, "没有找到") {
+		t.Errorf("expected empty-result message, got %q", res.Output)
+	}
+}
+
+func TestContextWithToolTimeout(t *testing.T) {
+	done := make(chan struct{})
+	c, cancel := (&Context{Config: &types.Config{Timeout: 60}, Done: done}).WithToolTimeout()
+	defer cancel()
+	if dl, ok := c.Deadline(); !ok || time.Until(dl) < 50*time.Second {
+		t.Errorf("deadline should follow config.Timeout, got %v", dl)
+	}
+	close(done)
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("closing Done should cancel the context")
+	}
+}
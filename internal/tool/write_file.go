@@ -2,6 +2,7 @@ package tool
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -28,9 +29,11 @@ func (t *WriteFileTool) Description() string {
 
 func (t *WriteFileTool) Parameters() interface{} {
 	return map[string]string{
-		"path":    "string (required) - file path to write",
-		"content": "string (required) - content to write",
-		"mode":    "string (optional) - 'append' or 'overwrite' (default)",
+		"path":          "string (required) - file path to write",
+		"content":       "string (required) - content to write",
+		"mode":          "string (optional) - 'append' or 'overwrite' (default)",
+		"perm":          "string (optional) - octal permission for a newly created file, e.g. '0755'; existing files keep their permission",
+		"expected_hash": "string (optional) - refuse to write unless the current file hash (from read_file) matches",
 	}
 }
 
@@ -39,6 +42,14 @@ func (t *WriteFileTool) Validate(args map[string]interface{}) error {
 	if !ok || path == "" {
 		return errors.New("path is required")
 	}
+	if mode, _ := args["mode"].(string); mode != "" && mode != "append" && mode != "overwrite" {
+		return fmt.Errorf("invalid mode %q (expected append or overwrite)", mode)
+	}
+	if perm, _ := args["perm"].(string); perm != "" {
+		if _, err := parseFileMode(perm); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -61,37 +72,50 @@ func (t *WriteFileTool) Execute(ctx *Context) *Result {
 		return result
 	}
 
-	if mode == "append" {
-		if err := os.MkdirAll(filepath.Dir(safePath), 0755); err != nil {
-			result.Status = "error"
-			result.Error = err.Error()
-			return result
-		}
-		f, err := os.OpenFile(safePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err := checkWritable(ctx.Config.RootDir, safePath); err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	if err := checkFresh(ctx, safePath); err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+
+	perm := defaultFileMode
+	if s, _ := ctx.Args["perm"].(string); s != "" {
+		perm, err = parseFileMode(s)
 		if err != nil {
 			result.Status = "error"
 			result.Error = err.Error()
 			return result
 		}
-		defer f.Close()
-		if _, err := f.WriteString(content); err != nil {
-			result.Status = "error"
-			result.Error = err.Error()
-			return result
-		}
-	} else {
-		if err := os.MkdirAll(filepath.Dir(safePath), 0755); err != nil {
-			result.Status = "error"
-			result.Error = err.Error()
-			return result
-		}
-		if err := os.WriteFile(safePath, []byte(content), 0644); err != nil {
+	}
+	data := []byte(content)
+	switch mode {
+	case "append":
+		existing, err := os.ReadFile(safePath)
+		if err != nil && !os.IsNotExist(err) {
 			result.Status = "error"
 			result.Error = err.Error()
 			return result
 		}
+		data = append(existing, data...)
+	case "", "overwrite":
+	default:
+		result.Status = "error"
+		result.Error = fmt.Sprintf("invalid mode %q (expected append or overwrite)", mode)
+		return result
+	}
+
+	if err := writeFileAtomic(safePath, data, perm); err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
 	}
 
+	recordWrite(ctx, safePath)
 	result.Status = "success"
 	result.Output = "写入成功"
 	result.StopStream = true
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ProjectConfigFile 是项目级配置文件相对工作目录的路径。
+const ProjectConfigFile = ".openlink/config.json"
+
+// ProjectConfig 是工作目录下 .openlink/config.json 中的项目级配置。
+type ProjectConfig struct {
+	// HTTPAllowHosts 是 http_request 工具除本机回环地址外允许访问的主机，
+	// 形如 "api.dev.test" 或 "10.0.0.5:8080"（带端口时只放行该端口）。
+	HTTPAllowHosts []string `json:"http_allow_hosts,omitempty"`
+	// MaxDownloadBytes 是 web_fetch save_to 下载的大小上限（字节），0 表示使用默认值 100 MiB。
+	MaxDownloadBytes int64 `json:"max_download_bytes,omitempty"`
+	// SkillDirs 是 skill 目录的扫描顺序，同名 skill 以先找到的为准。相对路径基于工作目录，
+	// "~/" 开头表示用户主目录；为空时使用默认顺序。
+	SkillDirs []string `json:"skill_dirs,omitempty"`
+}
+
+// LoadProjectConfig 读取 rootDir 下的项目配置；文件不存在时返回零值配置。
+func LoadProjectConfig(rootDir string) (ProjectConfig, error) {
+	var pc ProjectConfig
+	path := filepath.Join(rootDir, ProjectConfigFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return pc, nil
+	}
+	if err != nil {
+		return pc, err
+	}
+	if err := json.Unmarshal(data, &pc); err != nil {
+		return pc, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	return pc, nil
+}
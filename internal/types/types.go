@@ -3,9 +3,10 @@ package types
 import "encoding/json"
 
 type ToolRequest struct {
-	Name   string                 `json:"name"`
-	Args   map[string]interface{} `json:"args"`
-	Reason string                 `json:"reason,omitempty"`
+	Name    string                 `json:"name"`
+	Args    map[string]interface{} `json:"args"`
+	Reason  string                 `json:"reason,omitempty"`
+	Session string                 `json:"session,omitempty"`
 }
 
 func (r *ToolRequest) UnmarshalJSON(data []byte) error {
@@ -14,6 +15,7 @@ func (r *ToolRequest) UnmarshalJSON(data []byte) error {
 		Args      map[string]interface{} `json:"args"`
 		Arguments map[string]interface{} `json:"arguments"`
 		Reason    string                 `json:"reason,omitempty"`
+		Session   string                 `json:"session,omitempty"`
 	}
 	var v raw
 	if err := json.Unmarshal(data, &v); err != nil {
@@ -21,6 +23,7 @@ func (r *ToolRequest) UnmarshalJSON(data []byte) error {
 	}
 	r.Name = v.Name
 	r.Reason = v.Reason
+	r.Session = v.Session
 	if v.Args != nil {
 		r.Args = v.Args
 	} else {
@@ -42,9 +45,29 @@ type Config struct {
 	Timeout       int
 	Token         string
 	DefaultPrompt []byte
+	Project       ProjectConfig
+	// Offline 为 true 时 web_fetch 只从本地缓存读取
+	Offline bool
+	Search  SearchConfig
 }
 
 type Settings struct {
-	Token     string `json:"token"`
-	CreatedAt string `json:"created_at"`
+	Token     string        `json:"token"`
+	CreatedAt string        `json:"created_at"`
+	Search    *SearchConfig `json:"search,omitempty"`
+}
+
+// SearchConfig 配置 web_search 使用的搜索后端（~/.openlink/settings.json 中的 search 字段）。
+type SearchConfig struct {
+	// Backend 为 "searxng"（SearXNG 兼容的 JSON 接口）或 "http"（通用模板接口），空表示未配置
+	Backend string `json:"backend,omitempty"`
+	// URL 对 searxng 是实例地址；对 http 是请求模板，{query} 与 {limit} 会被替换为转义后的值
+	URL string `json:"url,omitempty"`
+	// Headers 是附加的请求头，通常用于携带 API Key
+	Headers map[string]string `json:"headers,omitempty"`
+	// 以下字段仅用于 http 后端：结果数组的点分路径，以及结果中标题、链接、摘要的字段名
+	ResultsPath  string `json:"results_path,omitempty"`
+	TitleField   string `json:"title_field,omitempty"`
+	URLField     string `json:"url_field,omitempty"`
+	SnippetField string `json:"snippet_field,omitempty"`
 }
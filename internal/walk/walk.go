@@ -0,0 +1,26 @@
+// Package walk 提供遍历工作目录时共用的目录过滤规则，供文件工具、文件列表和指令文件查找使用。
+package walk
+
+import "sort"
+
+// skipDirs 是遍历工作目录时默认跳过的目录（VCS 元数据、依赖与构建产物）。
+var skipDirs = map[string]bool{
+	".git": true, ".svn": true, ".hg": true,
+	"node_modules": true, "vendor": true, ".venv": true, "__pycache__": true,
+	".next": true, "dist": true, "build": true, "target": true,
+}
+
+// SkipDir 判断名为 name 的目录是否默认跳过。
+func SkipDir(name string) bool {
+	return skipDirs[name]
+}
+
+// SkipDirNames 返回默认跳过的目录名，按名称排序；返回值是副本，修改它不影响过滤规则。
+func SkipDirNames() []string {
+	names := make([]string, 0, len(skipDirs))
+	for name := range skipDirs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
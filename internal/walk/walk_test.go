@@ -0,0 +1,23 @@
+package walk
+
+import "testing"
+
+func TestSkipDir(t *testing.T) {
+	for _, name := range []string{".git", "node_modules", "vendor", "dist"} {
+		if !SkipDir(name) {
+			t.Errorf("%s should be skipped", name)
+		}
+	}
+	if SkipDir("src") || SkipDir(".openlink") {
+		t.Error("source and openlink directories should not be skipped")
+	}
+
+	names := SkipDirNames()
+	if len(names) == 0 || names[0] != ".git" {
+		t.Fatalf("names = %v", names)
+	}
+	names[0] = "src"
+	if SkipDir("src") || !SkipDir(".git") {
+		t.Error("modifying the returned names must not change the rules")
+	}
+}
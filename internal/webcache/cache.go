@@ -0,0 +1,363 @@
+// Package webcache 实现 web_fetch 使用的本地 HTTP 磁盘缓存。
+//
+// 每个条目以 URL 的 SHA-256 命名，保存为 <key>.json（元数据）和 <key>.body（响应体）两个文件。
+// 缓存遵循 Cache-Control、Expires、ETag 与 Last-Modified：新鲜的条目直接返回，
+// 过期但带校验信息的条目发送条件请求，收到 304 时复用缓存内容。离线模式下只从缓存读取。
+package webcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header 是缓存在返回的响应上附加的状态头：hit、revalidated、miss 或 offline。
+const Header = "X-Openlink-Cache"
+
+// MaxBodySize 是可被缓存的最大响应体，更大的响应直接透传。
+const MaxBodySize = 8 * 1024 * 1024
+
+// heuristicCap 是仅有 Last-Modified 时启发式新鲜期的上限。
+const heuristicCap = 24 * time.Hour
+
+// ErrNotCached 表示离线模式下请求的 URL 不在缓存中。
+var ErrNotCached = errors.New("not in web cache (offline mode)")
+
+// Entry 是一个缓存条目的元数据。
+type Entry struct {
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header"`
+	StoredAt   time.Time   `json:"stored_at"`
+	FreshUntil time.Time   `json:"fresh_until"`
+	Size       int64       `json:"size"`
+}
+
+// Cache 是位于 Dir 下的磁盘缓存。
+type Cache struct {
+	Dir string
+	// Offline 为 true 时只从缓存读取（不论是否过期），缓存未命中返回 ErrNotCached。
+	Offline bool
+	// now 便于测试控制时间。
+	now func() time.Time
+}
+
+// New 返回位于 dir 的缓存；目录在首次写入时创建。
+func New(dir string) *Cache {
+	return &Cache{Dir: dir, now: time.Now}
+}
+
+// DefaultDir 返回默认缓存目录 ~/.openlink/cache/web。
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".openlink", "cache", "web"), nil
+}
+
+func key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) metaPath(k string) string { return filepath.Join(c.Dir, k+".json") }
+func (c *Cache) bodyPath(k string) string { return filepath.Join(c.Dir, k+".body") }
+
+// Transport 返回包装 next 的 RoundTripper；只有 GET 请求会经过缓存。
+func (c *Cache) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{cache: c, next: next}
+}
+
+type transport struct {
+	cache *Cache
+	next  http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c := t.cache
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		if c.Offline {
+			return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL, ErrNotCached)
+		}
+		return t.next.RoundTrip(req)
+	}
+
+	url := req.URL.String()
+	entry, body, err := c.load(url)
+	if err != nil && !os.IsNotExist(err) {
+		entry = nil
+	}
+	if c.Offline {
+		if entry == nil {
+			return nil, fmt.Errorf("%s: %w", url, ErrNotCached)
+		}
+		return entry.response(req, body, "offline"), nil
+	}
+	if entry != nil && c.now().Before(entry.FreshUntil) {
+		return entry.response(req, body, "hit"), nil
+	}
+
+	if entry != nil {
+		req = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lm := entry.Header.Get("Last-Modified"); lm != "" {
+			req.Header.Set("If-Modified-Since", lm)
+		}
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+		// 304 携带的头（如新的 Cache-Control、ETag）覆盖旧值
+		for k, v := range resp.Header {
+			entry.Header[k] = v
+		}
+		entry.StoredAt = c.now()
+		entry.FreshUntil = freshUntil(entry.Header, entry.StoredAt)
+		c.saveMeta(url, entry)
+		return entry.response(req, body, "revalidated"), nil
+	}
+	if !cacheable(resp) {
+		resp.Header.Set(Header, "miss")
+		return resp, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(data) > MaxBodySize {
+		// 过大的响应不缓存，把已读部分与剩余部分拼回去
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+		resp.Header.Set(Header, "miss")
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	now := c.now()
+	entry = &Entry{
+		URL:        url,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		StoredAt:   now,
+		FreshUntil: freshUntil(resp.Header, now),
+		Size:       int64(len(data)),
+	}
+	c.store(url, entry, data)
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	resp.ContentLength = int64(len(data))
+	resp.Header.Set(Header, "miss")
+	return resp, nil
+}
+
+// cacheable 判断响应是否可存储：成功响应与永久重定向，且未声明 no-store。
+func cacheable(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusMovedPermanently, http.StatusPermanentRedirect:
+	default:
+		return false
+	}
+	_, noStore := cacheControl(resp.Header)["no-store"]
+	return !noStore
+}
+
+// cacheControl 解析 Cache-Control 头为指令表（指令名小写）。
+func cacheControl(h http.Header) map[string]string {
+	out := map[string]string{}
+	for _, line := range h.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if name == "" {
+				continue
+			}
+			out[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return out
+}
+
+// freshUntil 计算响应在 storedAt 之后保持新鲜的截止时间：
+// no-cache 总是需要重新校验；其次依次使用 max-age（扣除 Age）、Expires，
+// 最后对带 Last-Modified 的响应取其距今时长的 10%（不超过 24 小时）。
+func freshUntil(h http.Header, storedAt time.Time) time.Time {
+	cc := cacheControl(h)
+	if _, ok := cc["no-cache"]; ok {
+		return storedAt
+	}
+	if v, ok := cc["max-age"]; ok {
+		secs, err := strconv.Atoi(v)
+		if err != nil {
+			return storedAt
+		}
+		if age, err := strconv.Atoi(h.Get("Age")); err == nil {
+			secs -= age
+		}
+		return storedAt.Add(time.Duration(secs) * time.Second)
+	}
+	date := storedAt
+	if d, err := http.ParseTime(h.Get("Date")); err == nil {
+		date = d
+	}
+	if exp := h.Get("Expires"); exp != "" {
+		t, err := http.ParseTime(exp)
+		if err != nil {
+			return storedAt
+		}
+		return storedAt.Add(t.Sub(date))
+	}
+	if lm, err := http.ParseTime(h.Get("Last-Modified")); err == nil && lm.Before(date) {
+		ttl := date.Sub(lm) / 10
+		if ttl > heuristicCap {
+			ttl = heuristicCap
+		}
+		return storedAt.Add(ttl)
+	}
+	return storedAt
+}
+
+func (e *Entry) response(req *http.Request, body []byte, state string) *http.Response {
+	h := e.Header.Clone()
+	h.Set(Header, state)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func (c *Cache) load(url string) (*Entry, []byte, error) {
+	k := key(url)
+	data, err := os.ReadFile(c.metaPath(k))
+	if err != nil {
+		return nil, nil, err
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, nil, err
+	}
+	if e.URL != url {
+		return nil, nil, os.ErrNotExist
+	}
+	body, err := os.ReadFile(c.bodyPath(k))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &e, body, nil
+}
+
+// store 写入响应体与元数据；写入失败只影响缓存，不影响本次请求。
+func (c *Cache) store(url string, e *Entry, body []byte) {
+	k := key(url)
+	if err := writeFile(c.bodyPath(k), body); err != nil {
+		return
+	}
+	c.saveMeta(url, e)
+}
+
+func (c *Cache) saveMeta(url string, e *Entry) {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return
+	}
+	writeFile(c.metaPath(key(url)), data)
+}
+
+// writeFile 通过临时文件加 rename 写入，避免并发读取到半个文件。
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// List 返回所有缓存条目，按存储时间从新到旧排序。
+func (c *Cache) List() ([]Entry, error) {
+	matches, err := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, m := range matches {
+		data, err := os.ReadFile(m)
+		if err != nil {
+			continue
+		}
+		var e Entry
+		if json.Unmarshal(data, &e) == nil && e.URL != "" {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].StoredAt.After(entries[j].StoredAt) })
+	return entries, nil
+}
+
+// Fresh 报告条目当前是否仍然新鲜。
+func (c *Cache) Fresh(e Entry) bool { return c.now().Before(e.FreshUntil) }
+
+// Purge 删除满足 match 的条目并返回删除数量；match 为 nil 时清空整个缓存。
+func (c *Cache) Purge(match func(Entry) bool) (int, error) {
+	entries, err := c.List()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, e := range entries {
+		if match != nil && !match(e) {
+			continue
+		}
+		k := key(e.URL)
+		os.Remove(c.bodyPath(k))
+		if err := os.Remove(c.metaPath(k)); err != nil && !os.IsNotExist(err) {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,149 @@
+package webcache
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testCache 返回时间可控的缓存与客户端。
+func testCache(t *testing.T) (*Cache, *http.Client, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(t.TempDir())
+	c.now = func() time.Time { return now }
+	return c, &http.Client{Transport: c.Transport(nil)}, &now
+}
+
+func get(t *testing.T, client *http.Client, url string) (string, string) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body), resp.Header.Get(Header)
+}
+
+func TestMaxAge(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		w.Write([]byte("v1"))
+	}))
+	defer srv.Close()
+	_, client, now := testCache(t)
+
+	if body, state := get(t, client, srv.URL); body != "v1" || state != "miss" {
+		t.Fatalf("first fetch: %q %s", body, state)
+	}
+	if body, state := get(t, client, srv.URL); body != "v1" || state != "hit" {
+		t.Fatalf("second fetch: %q %s", body, state)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("expected 1 upstream hit, got %d", hits.Load())
+	}
+	*now = now.Add(2 * time.Minute)
+	if _, state := get(t, client, srv.URL); state != "miss" {
+		t.Errorf("expected refetch after expiry, got %s", state)
+	}
+}
+
+func TestRevalidation(t *testing.T) {
+	var conditional atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("etag body"))
+	}))
+	defer srv.Close()
+	_, client, _ := testCache(t)
+
+	get(t, client, srv.URL)
+	body, state := get(t, client, srv.URL)
+	if body != "etag body" || state != "revalidated" || conditional.Load() != 1 {
+		t.Errorf("expected revalidated cached body, got %q %s (%d conditional)", body, state, conditional.Load())
+	}
+}
+
+func TestLastModifiedHeuristic(t *testing.T) {
+	h := http.Header{}
+	stored := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	h.Set("Date", stored.Format(http.TimeFormat))
+	h.Set("Last-Modified", stored.Add(-10*time.Hour).Format(http.TimeFormat))
+	if got := freshUntil(h, stored); !got.Equal(stored.Add(time.Hour)) {
+		t.Errorf("expected 10%% heuristic, got %v", got.Sub(stored))
+	}
+	h.Set("Last-Modified", stored.Add(-1000*time.Hour).Format(http.TimeFormat))
+	if got := freshUntil(h, stored); !got.Equal(stored.Add(heuristicCap)) {
+		t.Errorf("expected heuristic cap, got %v", got.Sub(stored))
+	}
+	h.Set("Expires", stored.Add(5*time.Minute).Format(http.TimeFormat))
+	if got := freshUntil(h, stored); !got.Equal(stored.Add(5 * time.Minute)) {
+		t.Errorf("expected Expires to win, got %v", got.Sub(stored))
+	}
+	h.Set("Cache-Control", "max-age=30")
+	h.Set("Age", "10")
+	if got := freshUntil(h, stored); !got.Equal(stored.Add(20 * time.Second)) {
+		t.Errorf("expected max-age minus Age, got %v", got.Sub(stored))
+	}
+}
+
+func TestNoStoreAndOffline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/secret" {
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		w.Write([]byte("page " + r.URL.Path))
+	}))
+	c, client, _ := testCache(t)
+	get(t, client, srv.URL+"/doc")
+	get(t, client, srv.URL+"/secret")
+	srv.Close()
+
+	c.Offline = true
+	if body, state := get(t, client, srv.URL+"/doc"); body != "page /doc" || state != "offline" {
+		t.Errorf("expected offline replay, got %q %s", body, state)
+	}
+	if _, err := client.Get(srv.URL + "/secret"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("no-store response must not be cached, got %v", err)
+	}
+}
+
+func TestListAndPurge(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 10)))
+	}))
+	defer srv.Close()
+	c, client, now := testCache(t)
+	get(t, client, srv.URL+"/a")
+	*now = now.Add(time.Hour)
+	get(t, client, srv.URL+"/b")
+
+	entries, err := c.List()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d (%v)", len(entries), err)
+	}
+	if !strings.HasSuffix(entries[0].URL, "/b") || entries[0].Size != 10 {
+		t.Errorf("expected newest first with size, got %+v", entries[0])
+	}
+
+	n, _ := c.Purge(func(e Entry) bool { return strings.HasSuffix(e.URL, "/a") })
+	if entries, _ = c.List(); n != 1 || len(entries) != 1 {
+		t.Errorf("expected selective purge, removed %d left %d", n, len(entries))
+	}
+	if n, _ = c.Purge(nil); n != 1 {
+		t.Errorf("expected purge all to remove 1, got %d", n)
+	}
+}